@@ -1,18 +1,11 @@
 package sort
 
 import (
-	"cmp"
 	"fmt"
 	"math"
-	"slices"
 	"testing"
 )
 
-func slicesSort[T cmp.Ordered](items []T) []T {
-	slices.Sort(items)
-	return items
-}
-
 func BenchmarkSort(b *testing.B) {
 	tests := []struct {
 		Name string
@@ -22,6 +15,8 @@ func BenchmarkSort(b *testing.B) {
 		{"MergeSort", MergeSort[uint64]},
 		{"QuickSort", QuickSort[uint64]},
 		{"RadixSort", RadixSort[uint64]},
+		{"Sort", Sort[uint64]},
+		{"SortStable", SortStable[uint64]},
 		{"slices.Sort", slicesSort[uint64]},
 	}
 	for _, tt := range tests {
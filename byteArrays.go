@@ -0,0 +1,121 @@
+package sort
+
+import (
+	"bytes"
+	"slices"
+	"unsafe"
+)
+
+// ByteArray is a constraint for fixed-size byte arrays commonly used as keys, such as IPv4 addresses, UUIDs and cryptographic hashes.
+// Arrays are ordered by comparing their bytes lexicographically, just like bytes.Compare.
+type ByteArray interface {
+	~[4]byte | ~[8]byte | ~[12]byte | ~[16]byte | ~[20]byte | ~[24]byte | ~[28]byte | ~[32]byte | ~[48]byte | ~[64]byte
+}
+
+// CompareArrays compares two byte arrays lexicographically.
+// It returns -1 if a is less than b, 0 if they are equal and +1 if a is greater than b.
+func CompareArrays[T ByteArray](a, b T) int {
+	return bytes.Compare(arrayBytes(&a), arrayBytes(&b))
+}
+
+// RadixSortArrays sorts byte arrays using a most significant digit radix sort.
+// Every pass distributes the items into buckets by a single byte and only buckets containing more than one item are sorted by the following bytes.
+// For uniformly distributed keys like hashes this means that only the first few bytes are ever looked at.
+func RadixSortArrays[T ByteArray](items []T) []T {
+	if len(items) < 2 {
+		return items
+	}
+	// Slices of empty structs do not allocate memory and moving their items is free
+	vals := make([]struct{}, len(items))
+	msdRadixSortArrays(items, vals, make([]T, len(items)), make([]struct{}, len(items)), 0)
+	return items
+}
+
+// RadixSortByArray sorts items by a byte array key, e.g. structs by a hash or ID field.
+// The key is retrieved only once per item and the items are moved along with their keys.
+// The sort is stable, therefore items with equal keys retain their original order.
+func RadixSortByArray[T any, K ByteArray](items []T, key func(T) K) []T {
+	if len(items) < 2 {
+		return items
+	}
+	keys := make([]K, len(items))
+	for i, v := range items {
+		keys[i] = key(v)
+	}
+	msdRadixSortArrays(keys, items, make([]K, len(keys)), make([]T, len(items)), 0)
+	return items
+}
+
+// UniqueArrays sorts byte arrays and removes duplicates, returning the shortened slice.
+// Elements between the new length and the original length are zeroed.
+func UniqueArrays[T ByteArray](items []T) []T {
+	return slices.Compact(RadixSortArrays(items))
+}
+
+// SearchArrays searches for target in a sorted slice of byte arrays and returns the position where target is found, or the position where target would appear in the sort order.
+// It also returns a bool saying whether the target is really found in the slice.
+func SearchArrays[T ByteArray](items []T, target T) (int, bool) {
+	return slices.BinarySearchFunc(items, target, CompareArrays)
+}
+
+// SearchByArray works like SearchArrays, but for items sorted by a byte array key, e.g. using RadixSortByArray.
+func SearchByArray[T any, K ByteArray](items []T, target K, key func(T) K) (int, bool) {
+	return slices.BinarySearchFunc(items, target, func(item T, target K) int { return CompareArrays(key(item), target) })
+}
+
+// arrayBytes returns a slice sharing the memory of the byte array a.
+func arrayBytes[T ByteArray](a *T) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(a)), len(*a))
+}
+
+// msdRadixSortArrays sorts keys and vals by the bytes of the keys starting at depth, which are known to have an identical prefix before it.
+// It works like msdRadixSort, but since all keys have the same length, no bucket for keys ending early is needed.
+// The temporary slices have to be the same length as keys and vals.
+func msdRadixSortArrays[K ByteArray, V any](keys []K, vals []V, tmpK []K, tmpV []V, depth int) {
+	for ; depth < len(keys[0]); depth++ {
+		if len(keys) <= msdInsertionSize {
+			for i := range keys {
+				for position := i; position > 0 && bytes.Compare(arrayBytes(&keys[position-1])[depth:], arrayBytes(&keys[position])[depth:]) > 0; position-- {
+					keys[position], keys[position-1] = keys[position-1], keys[position]
+					vals[position], vals[position-1] = vals[position-1], vals[position]
+				}
+			}
+			return
+		}
+
+		// Create buckets and count items
+		bucket := [257]int{}
+		for i := range keys {
+			bucket[int(keys[i][depth])+1]++
+		}
+
+		// A single bucket containing all items means this byte does not influence the order
+		if bucket[int(keys[0][depth])+1] == len(keys) {
+			continue
+		}
+
+		// Add count from previous bucket, shifted by one so that each bucket starts at the end of the previous one
+		for i := 1; i < 257; i++ {
+			bucket[i] += bucket[i-1]
+		}
+
+		// Fill the temporary slices in the original order to keep the sort stable and copy them back
+		starts := bucket
+		for i := range keys {
+			b := &bucket[keys[i][depth]]
+			tmpK[*b] = keys[i]
+			tmpV[*b] = vals[i]
+			*b++
+		}
+		copy(keys, tmpK[:len(keys)])
+		copy(vals, tmpV[:len(vals)])
+
+		// Sort the buckets individually by the following bytes, terminating early for buckets with less than two items
+		for b := 0; b < 256; b++ {
+			if starts[b+1]-starts[b] > 1 {
+				msdRadixSortArrays(keys[starts[b]:starts[b+1]], vals[starts[b]:starts[b+1]], tmpK, tmpV, depth+1)
+			}
+		}
+		return
+	}
+}
@@ -0,0 +1,124 @@
+package sort
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// Sizes used to find the crossover points between the algorithms.
+var (
+	calibrateInsertionSizes = []int{4, 8, 12, 16, 24, 32, 48, 64}
+	calibrateRadixSizes     = []int{64, 128, 256, 512, 1024, 2048, 4096, 8192}
+)
+
+// Calibrate measures the performance of the algorithms used by Sort and SortStable on the current machine and returns thresholds based on the results.
+// The measurements are performed on random uint64 and uint32 slices of different sizes, using the higher crossover point of both types.
+// Budget is the approximate total time spent on measurements and is divided evenly among them while running, so a larger budget leads to more precise results.
+// If ctx is cancelled before all measurements are done, the values that could not be determined are taken from DefaultThresholds.
+// The result can be applied globally using SetThresholds or to individual calls using SortWith and SortStableWith.
+func Calibrate(ctx context.Context, budget time.Duration) Thresholds {
+	// Each size is measured once for insertion sort and twice, for uint64 and uint32, for both radix sort thresholds
+	c := &calibration{
+		ctx:      ctx,
+		deadline: time.Now().Add(budget),
+		pending:  len(calibrateInsertionSizes) + len(calibrateRadixSizes)*2*2,
+	}
+	t := DefaultThresholds
+
+	if n, ok := c.largestFaster(InsertionSort[uint64], slicesSort[uint64], calibrateInsertionSizes); ok {
+		t.Insertion = n
+	}
+	if n, ok := c.smallestFaster(RadixSort[uint64], slicesSort[uint64], RadixSort[uint32], slicesSort[uint32]); ok {
+		t.Radix = n
+	}
+	if n, ok := c.smallestFaster(RadixSort[uint64], MergeSort[uint64], RadixSort[uint32], MergeSort[uint32]); ok {
+		t.RadixStable = n
+	}
+	return t
+}
+
+// calibration holds the state shared by all measurements of a single call to Calibrate.
+// Pending is the number of measurements that may still be performed, which the remaining time until the deadline is divided by.
+// Measurements skipped because a crossover point has been found are removed from it, so that their time is used by the remaining ones.
+type calibration struct {
+	ctx      context.Context
+	deadline time.Time
+	pending  int
+}
+
+// largestFaster returns the largest of the sizes up to which fast is faster than slow for uint64.
+func (c *calibration) largestFaster(fast, slow func([]uint64) []uint64, sizes []int) (int, bool) {
+	result := 0
+	for i, n := range sizes {
+		f, s, ok := compareSpeed(c, fast, slow, n)
+		if !ok {
+			return 0, false
+		}
+		if f > s {
+			c.pending -= len(sizes) - i - 1
+			break
+		}
+		result = n
+	}
+	return result, true
+}
+
+// smallestFaster returns the smallest size starting at which fast is faster than slow for both uint64 and uint32.
+func (c *calibration) smallestFaster(fast64, slow64 func([]uint64) []uint64, fast32, slow32 func([]uint32) []uint32) (int, bool) {
+	// Search from the largest size downwards so that noise at small sizes cannot lower the threshold
+	result := math.MaxInt
+	for i, n := range slices.Backward(calibrateRadixSizes) {
+		f64, s64, ok := compareSpeed(c, fast64, slow64, n)
+		if !ok {
+			return 0, false
+		}
+		f32, s32, ok := compareSpeed(c, fast32, slow32, n)
+		if !ok {
+			return 0, false
+		}
+		if f64 > s64 || f32 > s32 {
+			c.pending -= i * 2
+			break
+		}
+		result = n
+	}
+	return result, true
+}
+
+// compareSpeed measures the time per operation of two algorithms on the same random input of size n.
+// Both algorithms share an equal part of the time remaining until the deadline.
+func compareSpeed[T uint64 | uint32](c *calibration, a, b func([]T) []T, n int) (time.Duration, time.Duration, bool) {
+	if c.ctx.Err() != nil {
+		return 0, 0, false
+	}
+	step := time.Until(c.deadline) / time.Duration(max(c.pending, 1))
+	c.pending--
+	input := make([]T, n)
+	for i := range input {
+		input[i] = T(rand.Uint64())
+	}
+	return measure(a, input, step/2), measure(b, input, step/2), true
+}
+
+// measure returns the average time a sorting function takes to sort a copy of input, running it repeatedly for at least the given duration.
+func measure[T cmp.Ordered](fn func([]T) []T, input []T, duration time.Duration) time.Duration {
+	data := make([]T, len(input))
+	start := time.Now()
+	for runs := 1; ; runs++ {
+		copy(data, input)
+		fn(data)
+		if elapsed := time.Since(start); elapsed >= duration {
+			return elapsed / time.Duration(runs)
+		}
+	}
+}
+
+// slicesSort wraps slices.Sort to match the signature of the other sorting functions.
+func slicesSort[T cmp.Ordered](items []T) []T {
+	slices.Sort(items)
+	return items
+}
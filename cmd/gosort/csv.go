@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/fossoreslp/go-sort/csvsort"
+)
+
+// csvConfig holds the options for sorting rows of CSV files.
+type csvConfig struct {
+	comma   rune
+	header  bool
+	reverse bool
+	output  string
+}
+
+// run sorts the rows of the files, returning the exit status.
+func (c csvConfig) run(keyDefs []string, files []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fail := func(err error) int {
+		fmt.Fprintln(stderr, "gosort:", err)
+		return 2
+	}
+	opts := csvsort.Options{Comma: c.comma, Header: c.header}
+	for _, s := range keyDefs {
+		col, err := csvsort.ParseColumn(s)
+		if err != nil {
+			return fail(err)
+		}
+		col.Descending = col.Descending != c.reverse
+		opts.Columns = append(opts.Columns, col)
+	}
+	if len(opts.Columns) == 0 && c.reverse {
+		return fail(errors.New("-r requires keys with -C"))
+	}
+
+	in, err := openInputs(files, stdin)
+	if err != nil {
+		return fail(err)
+	}
+	defer in.Close()
+
+	out := newOutput(c.output, stdout)
+	defer out.Close()
+	if err := csvsort.Sort(out, opts, in.readers...); err != nil {
+		return fail(err)
+	}
+	if err := out.Close(); err != nil {
+		return fail(err)
+	}
+	return 0
+}
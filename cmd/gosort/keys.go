@@ -0,0 +1,253 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// config holds the options determining the order of lines.
+type config struct {
+	numeric   bool
+	reverse   bool
+	unique    bool
+	stable    bool
+	separator string
+	keys      []keyDef
+	// wholeLine is set if no keys are given, in which case lines are not split into fields
+	wholeLine bool
+}
+
+// keyDef describes a key as given by the -k flag.
+type keyDef struct {
+	startField, startChar int
+	// endField is 0 if the key extends to the end of the line and endChar is 0 if it extends to the end of endField
+	endField, endChar int
+	numeric, reverse  bool
+	// options is set if the key has its own options, which then override the global flags
+	options bool
+}
+
+// line is a line of input along with the values of its keys.
+type line struct {
+	text string
+	keys []keyValue
+}
+
+// keyValue is the value of a key of a line, with num only being set for numeric keys.
+type keyValue struct {
+	text string
+	num  number
+}
+
+// number is a decimal number normalized to its sign, its digits without leading and trailing zeros and a decimal exponent, e.g. 12.50 to 1, "125" and 2 for 0.125×10².
+// Unlike a floating point value, it represents numbers of any size and precision exactly.
+type number struct {
+	sign     int
+	digits   string
+	exponent int
+}
+
+// compare compares two numbers by their exact values.
+// For numbers with the same sign and exponent, comparing the digits as strings compares the numbers, while negative numbers need the opposite order.
+func (a number) compare(b number) int {
+	if r := cmp.Compare(a.sign, b.sign); r != 0 || a.sign == 0 {
+		return r
+	}
+	r := cmp.Compare(a.exponent, b.exponent)
+	if r == 0 {
+		r = strings.Compare(a.digits, b.digits)
+	}
+	return a.sign * r
+}
+
+// init applies the global options to the keys without their own options and adds a key for the whole line if no keys are given.
+func (c *config) init() {
+	if len(c.keys) == 0 {
+		c.keys = []keyDef{{startField: 1, startChar: 1}}
+		c.wholeLine = true
+	}
+	for i, k := range c.keys {
+		if !k.options {
+			c.keys[i].numeric, c.keys[i].reverse = c.numeric, c.reverse
+		}
+	}
+}
+
+// parse extracts the values of all keys from text.
+func (c *config) parse(text string) line {
+	l := line{text: text, keys: make([]keyValue, len(c.keys))}
+	if c.wholeLine {
+		l.keys[0].text = text
+	} else {
+		fields := c.fields(text)
+		for i, k := range c.keys {
+			l.keys[i].text = k.extract(text, fields)
+		}
+	}
+	for i, k := range c.keys {
+		if k.numeric {
+			l.keys[i].num = parseNumber(l.keys[i].text)
+		}
+	}
+	return l
+}
+
+// compare compares two lines by their keys and, if these are equal and neither -s nor -u is given, by their whole text.
+func (c *config) compare(a, b line) int {
+	for i, k := range c.keys {
+		var r int
+		if k.numeric {
+			r = a.keys[i].num.compare(b.keys[i].num)
+		} else {
+			r = strings.Compare(a.keys[i].text, b.keys[i].text)
+		}
+		if k.reverse {
+			r = -r
+		}
+		if r != 0 {
+			return r
+		}
+	}
+	if c.stable || c.unique {
+		return 0
+	}
+	if c.reverse {
+		return strings.Compare(b.text, a.text)
+	}
+	return strings.Compare(a.text, b.text)
+}
+
+// fields returns the start and end offsets of all fields of text.
+// Without a separator, every field consists of a run of blanks followed by a run of non-blank characters.
+func (c *config) fields(text string) [][2]int {
+	var fields [][2]int
+	if c.separator != "" {
+		start := 0
+		for {
+			end := strings.Index(text[start:], c.separator)
+			if end < 0 {
+				return append(fields, [2]int{start, len(text)})
+			}
+			fields = append(fields, [2]int{start, start + end})
+			start += end + len(c.separator)
+		}
+	}
+	for start := 0; start < len(text); {
+		end := start
+		for end < len(text) && isBlank(text[end]) {
+			end++
+		}
+		for end < len(text) && !isBlank(text[end]) {
+			end++
+		}
+		fields = append(fields, [2]int{start, end})
+		start = end
+	}
+	return fields
+}
+
+// extract returns the part of text specified by the key.
+func (k keyDef) extract(text string, fields [][2]int) string {
+	if k.startField > len(fields) {
+		return ""
+	}
+	field := fields[k.startField-1]
+	start := min(field[0]+k.startChar-1, field[1])
+	end := len(text)
+	if k.endField > 0 && k.endField <= len(fields) {
+		field = fields[k.endField-1]
+		end = field[1]
+		if k.endChar > 0 {
+			end = min(field[0]+k.endChar, end)
+		}
+	}
+	if end < start {
+		return ""
+	}
+	return text[start:end]
+}
+
+// parseKeyDef parses a key definition of the form F[.C][OPTS][,F[.C][OPTS]].
+func parseKeyDef(s string) (keyDef, error) {
+	var k keyDef
+	startDef, endDef, hasEnd := strings.Cut(s, ",")
+	var err error
+	if k.startField, k.startChar, err = k.parsePosition(startDef, 1); err != nil {
+		return k, err
+	}
+	if k.startField == 0 || k.startChar == 0 {
+		return k, errors.New("invalid key " + strconv.Quote(s) + ": the start field and character must be at least 1")
+	}
+	if hasEnd {
+		if k.endField, k.endChar, err = k.parsePosition(endDef, 0); err != nil {
+			return k, err
+		}
+		if k.endField == 0 {
+			return k, errors.New("invalid key " + strconv.Quote(s) + ": the end field must be at least 1")
+		}
+	}
+	return k, nil
+}
+
+// parsePosition parses a position of the form F[.C][OPTS], applying the options to the key.
+// If the character is omitted, defaultChar is returned, which is 1 for start positions and 0 for end positions.
+func (k *keyDef) parsePosition(s string, defaultChar int) (field, char int, err error) {
+	pos := strings.TrimRight(s, "nr")
+	for _, o := range s[len(pos):] {
+		k.options = true
+		switch o {
+		case 'n':
+			k.numeric = true
+		case 'r':
+			k.reverse = true
+		}
+	}
+	fieldDef, charDef, hasChar := strings.Cut(pos, ".")
+	if field, err = strconv.Atoi(fieldDef); err != nil || field < 0 {
+		return 0, 0, errors.New("invalid field in key position " + strconv.Quote(s))
+	}
+	if !hasChar {
+		return field, defaultChar, nil
+	}
+	if char, err = strconv.Atoi(charDef); err != nil || char < 0 {
+		return 0, 0, errors.New("invalid character in key position " + strconv.Quote(s))
+	}
+	return field, char, nil
+}
+
+// parseNumber returns the value of the number at the start of s after skipping blanks, which is 0 if there is none.
+// Numbers consist of an optional minus sign, digits and an optional decimal point, just like for the Unix sort command.
+func parseNumber(s string) number {
+	start := 0
+	for start < len(s) && isBlank(s[start]) {
+		start++
+	}
+	negative := start < len(s) && s[start] == '-'
+	if negative {
+		start++
+	}
+	end := start
+	point := false
+	for end < len(s) && ('0' <= s[end] && s[end] <= '9' || s[end] == '.' && !point) {
+		point = point || s[end] == '.'
+		end++
+	}
+	integer, fraction, _ := strings.Cut(s[start:end], ".")
+	digits := strings.TrimLeft(integer+fraction, "0")
+	n := number{sign: 1, exponent: len(digits) - len(fraction), digits: strings.TrimRight(digits, "0")}
+	switch {
+	case n.digits == "":
+		// Negative zero is equal to zero
+		return number{}
+	case negative:
+		n.sign = -1
+	}
+	return n
+}
+
+// isBlank reports whether b is a space or tab.
+func isBlank(b byte) bool {
+	return b == ' ' || b == '\t'
+}
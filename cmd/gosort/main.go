@@ -0,0 +1,393 @@
+// Command gosort sorts lines of text files like the Unix sort command.
+//
+// Usage:
+//
+//	gosort [flags] [file ...]
+//
+// The lines of all files are sorted and written to the standard output.
+// If no file or "-" is given, the standard input is read.
+// Inputs larger than the memory limit are sorted in parts, which are written to temporary files and merged afterwards.
+//
+// The flags are:
+//
+//	-n        compare by the numeric value at the start of the key
+//	-r        reverse the order
+//	-k KEYDEF sort by a key, may be given multiple times (see below)
+//	-t SEP    use SEP as the field separator instead of the transition from non-blank to blank characters
+//	-u        output only the first of lines with equal keys
+//	-s        stable sort, disabling the comparison of the whole lines for lines with equal keys
+//	-m        merge files that are already sorted
+//	-c        check whether the input is sorted, exiting with status 1 if it is not
+//	-o FILE   write the result to FILE instead of the standard output
+//	-S SIZE   memory limit, e.g. 512M or 2G (default 64M)
+//	-T DIR    directory for temporary files
+//	-R SIZE   sort binary records of SIZE bytes instead of lines (see below)
+//	-C        sort rows of CSV files instead of lines (see below)
+//	-H        keep the first row of CSV files as a header at the top
+//
+// A KEYDEF has the form F[.C][OPTS][,F[.C][OPTS]], specifying the field F and character C where the key starts and ends.
+// Fields and characters are counted starting at 1, and a missing or zero end character refers to the end of the field.
+// If the end is omitted, the key extends to the end of the line.
+// OPTS consists of the letters n and r, which override the global flags for this key.
+// Boolean flags can be combined, e.g. -nr.
+//
+// With -R SIZE, the input is treated as binary records of SIZE bytes instead of lines.
+// Keys then have the form OFFSET:LENGTH[:FLAGS], specifying an integer at a fixed position in every record.
+// FLAGS consists of the letters s for signed, l for little endian and r for descending order, e.g. -k 8:4:sl.
+// Records are always sorted stably, and only -r, -c, -o, -S and -T can be combined with -R.
+//
+// With -C, the input is treated as CSV files instead of lines, using the separator given by -t or a comma.
+// Fields may be quoted and then contain separators and line breaks.
+// Keys then have the form COLUMN[:TYPE][:FLAGS], specifying a column by its position starting at 1 or its name in the header, e.g. -k size:int:r.
+// TYPE is one of string (default), natural, int, float and time (RFC 3339) and FLAGS may consist of the letter r for descending order.
+// Without keys, rows are ordered by all of their fields in ascending order, so -r requires keys.
+// Rows are always sorted stably in memory, and only -t, -r, -s and -o can be combined with -C.
+//
+// The exit status is 0 on success, 1 if -c found the input not to be sorted and 2 if an error occurred.
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fossoreslp/go-sort/extsort"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command with the given arguments and returns the exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := &config{}
+	var check, merge bool
+	var output, tempDir string
+	memory := 64 << 20
+	fs := flag.NewFlagSet("gosort", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.BoolVar(&c.numeric, "n", false, "compare by the numeric value at the start of the key")
+	fs.BoolVar(&c.reverse, "r", false, "reverse the order")
+	var keys []string
+	fs.Func("k", "sort by a key of the form `F[.C][OPTS][,F[.C][OPTS]]`, may be given multiple times", func(s string) error {
+		keys = append(keys, s)
+		return nil
+	})
+	fs.Func("t", "use `SEP` as the field separator", func(s string) error {
+		if len(s) != 1 {
+			return errors.New("the separator must be a single byte")
+		}
+		c.separator = s
+		return nil
+	})
+	fs.BoolVar(&c.unique, "u", false, "output only the first of lines with equal keys")
+	fs.BoolVar(&c.stable, "s", false, "stable sort, disabling the comparison of the whole lines")
+	fs.BoolVar(&merge, "m", false, "merge files that are already sorted")
+	fs.BoolVar(&check, "c", false, "check whether the input is sorted")
+	fs.StringVar(&output, "o", "", "write the result to `FILE` instead of the standard output")
+	fs.Func("S", "memory limit `SIZE`, e.g. 512M or 2G (default 64M)", func(s string) (err error) {
+		memory, err = parseSize(s)
+		return err
+	})
+	fs.StringVar(&tempDir, "T", "", "`DIR`ectory for temporary files")
+	recordSize := fs.Int("R", 0, "sort binary records of `SIZE` bytes instead of lines")
+	csvMode := fs.Bool("C", false, "sort rows of CSV files instead of lines")
+	header := fs.Bool("H", false, "keep the first row of CSV files as a header")
+	if err := fs.Parse(expandFlags(args)); err != nil {
+		// The usage has already been printed
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	fail := func(err error) int {
+		fmt.Fprintln(stderr, "gosort:", err)
+		return 2
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	if check && len(files) > 1 {
+		return fail(errors.New("-c accepts only a single file"))
+	}
+	if *header && !*csvMode {
+		return fail(errors.New("-H can only be used with -C"))
+	}
+	if *csvMode {
+		// Rows are sorted in memory, so the flags for temporary files are rejected as well instead of being ignored
+		set := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if c.numeric || c.unique || merge || check || *recordSize != 0 || set["S"] || set["T"] {
+			return fail(errors.New("-n, -u, -m, -c, -R, -S and -T cannot be used with -C"))
+		}
+		r := csvConfig{header: *header, reverse: c.reverse, output: output}
+		if c.separator != "" {
+			r.comma = rune(c.separator[0])
+		}
+		return r.run(keys, files, stdin, stdout, stderr)
+	}
+	if *recordSize != 0 {
+		if c.numeric || c.unique || c.separator != "" || merge {
+			return fail(errors.New("-n, -t, -u and -m cannot be used with -R"))
+		}
+		r := recordConfig{size: *recordSize, reverse: c.reverse, check: check, output: output}
+		return r.run(keys, files, extsort.Options[[]byte]{MemoryLimit: memory, TempDir: tempDir}, stdin, stdout, stderr)
+	}
+	for _, s := range keys {
+		k, err := parseKeyDef(s)
+		if err != nil {
+			return fail(err)
+		}
+		c.keys = append(c.keys, k)
+	}
+	c.init()
+
+	inputs := make([]iter.Seq2[line, error], len(files))
+	for i, name := range files {
+		inputs[i] = c.readLines(name, stdin)
+	}
+	if check {
+		return c.check(files[0], inputs[0], stderr)
+	}
+
+	var sorted func(fn func(line) error) error
+	if merge {
+		sorted = func(fn func(line) error) error { return extsort.Merge(c.compare, fn, inputs...) }
+	} else {
+		s := extsort.New(c.compare, lineCodec{c}, extsort.Options[line]{Size: lineSize, MemoryLimit: memory, TempDir: tempDir})
+		defer s.Close()
+		for _, input := range inputs {
+			for l, err := range input {
+				if err != nil {
+					return fail(err)
+				}
+				if err := s.Add(l); err != nil {
+					return fail(err)
+				}
+			}
+		}
+		sorted = s.Each
+	}
+
+	out := newOutput(output, stdout)
+	defer out.Close()
+	w := bufio.NewWriter(out)
+	var previous *line
+	err := sorted(func(l line) error {
+		if c.unique && previous != nil && c.compare(*previous, l) == 0 {
+			return nil
+		}
+		previous = &l
+		w.WriteString(l.text)
+		return w.WriteByte('\n')
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = out.Close()
+	}
+	if err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+// output writes to the named file, which is only created on the first write or when closing it.
+// Therefore the file can be one of the input files, unless the input is still read while writing, like when merging.
+// If the name is empty, it writes to stdout instead.
+type output struct {
+	name string
+	w    io.Writer
+	f    *os.File
+}
+
+func newOutput(name string, stdout io.Writer) *output {
+	if name == "" {
+		return &output{w: stdout}
+	}
+	return &output{name: name}
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	if err := o.create(); err != nil {
+		return 0, err
+	}
+	return o.w.Write(p)
+}
+
+// Close creates the file if nothing has been written and closes it. Calling it multiple times has no further effect.
+func (o *output) Close() error {
+	if err := o.create(); err != nil {
+		return err
+	}
+	if o.f == nil {
+		return nil
+	}
+	err := o.f.Close()
+	o.f = nil
+	return err
+}
+
+// create creates the file if it has not been created yet.
+func (o *output) create() error {
+	if o.w != nil {
+		return nil
+	}
+	f, err := os.Create(o.name)
+	if err != nil {
+		return err
+	}
+	o.f, o.w = f, f
+	return nil
+}
+
+// check reports the first line of input that is out of order, returning the exit status.
+func (c *config) check(name string, input iter.Seq2[line, error], stderr io.Writer) int {
+	var previous *line
+	number := 0
+	for l, err := range input {
+		if err != nil {
+			fmt.Fprintln(stderr, "gosort:", err)
+			return 2
+		}
+		number++
+		if previous != nil {
+			// Unique output requires strictly increasing keys
+			if r := c.compare(*previous, l); r > 0 || (r == 0 && c.unique) {
+				fmt.Fprintf(stderr, "gosort: %s:%d: disorder: %s\n", name, number, l.text)
+				return 1
+			}
+		}
+		previous = &l
+	}
+	return 0
+}
+
+// readLines returns an iterator over the lines of the named file, which is read from stdin if it is "-".
+// Lines are terminated by a newline, which is not included in the lines, while the last line may omit it.
+func (c *config) readLines(name string, stdin io.Reader) iter.Seq2[line, error] {
+	return func(yield func(line, error) bool) {
+		in := stdin
+		if name != "-" {
+			f, err := os.Open(name)
+			if err != nil {
+				yield(line{}, err)
+				return
+			}
+			defer f.Close()
+			in = f
+		}
+		r := bufio.NewReader(in)
+		for {
+			text, err := r.ReadString('\n')
+			if text == "" && err == io.EOF {
+				return
+			}
+			if err != nil && err != io.EOF {
+				yield(line{}, err)
+				return
+			}
+			if !yield(c.parse(strings.TrimSuffix(text, "\n")), nil) {
+				return
+			}
+		}
+	}
+}
+
+// lineSize approximates the memory used by a line including its keys.
+func lineSize(l line) int {
+	return len(l.text) + 48 + len(l.keys)*32
+}
+
+// lineCodec writes lines to temporary files, prefixed by their length, and parses their keys again when reading them back.
+type lineCodec struct {
+	c *config
+}
+
+func (lc lineCodec) Encode(w *bufio.Writer, l line) error {
+	w.Write(binary.AppendUvarint(nil, uint64(len(l.text))))
+	_, err := w.WriteString(l.text)
+	return err
+}
+
+func (lc lineCodec) Decode(r *bufio.Reader) (line, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return line{}, err
+	}
+	text := make([]byte, n)
+	if _, err := io.ReadFull(r, text); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return line{}, err
+	}
+	return lc.c.parse(string(text)), nil
+}
+
+// expandFlags splits combined flags like -nr or -nk2 into separate flags, with the value of a flag taking a value being the rest of the argument or the next argument.
+// Long flags like --help are passed through unchanged. It stops at the first argument that is not a flag.
+func expandFlags(args []string) []string {
+	var expanded []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || arg == "-" || !strings.HasPrefix(arg, "-") {
+			return append(expanded, args[i:]...)
+		}
+		if strings.HasPrefix(arg, "--") || len(arg) > 2 && arg[2] == '=' {
+			expanded = append(expanded, arg)
+			// Long forms of flags taking a value like --k 2 are followed by the value
+			if name := arg[2:]; len(name) == 1 && strings.Contains("ktoSTR", name) && i+1 < len(args) {
+				i++
+				expanded = append(expanded, args[i])
+			}
+			continue
+		}
+		for j := 1; j < len(arg); j++ {
+			expanded = append(expanded, "-"+arg[j:j+1])
+			if !strings.Contains("ktoSTR", arg[j:j+1]) {
+				continue
+			}
+			// The value may start with a dash, e.g. -t -
+			if j+1 < len(arg) {
+				expanded = append(expanded, arg[j+1:])
+			} else if i+1 < len(args) {
+				i++
+				expanded = append(expanded, args[i])
+			}
+			break
+		}
+	}
+	return expanded
+}
+
+// parseSize parses a memory size consisting of a number followed by an optional suffix K, M or G.
+func parseSize(s string) (int, error) {
+	shift := 0
+	switch strings.ToUpper(s[len(s)-min(len(s), 1):]) {
+	case "K":
+		shift = 10
+	case "M":
+		shift = 20
+	case "G":
+		shift = 30
+	}
+	if shift > 0 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0, errors.New("invalid size")
+	}
+	return n << shift, nil
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func runTest(t *testing.T, args []string, stdin string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	status := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), stderr.String(), status
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Args  []string
+		Input string
+		Want  string
+	}{
+		{"default", nil, "banana\napple\ncherry\n", "apple\nbanana\ncherry\n"},
+		{"missing newline", nil, "b\na", "a\nb\n"},
+		{"empty", nil, "", ""},
+		{"empty lines", nil, "b\n\na\n", "\na\nb\n"},
+		{"numeric", []string{"-n"}, "10\n9\n-1.5\nx\n100\n", "-1.5\nx\n9\n10\n100\n"},
+		{"reverse", []string{"-r"}, "a\nc\nb\n", "c\nb\na\n"},
+		{"numeric reverse", []string{"-nr"}, "10\n9\n100\n", "100\n10\n9\n"},
+		{"numeric exact", []string{"-n"}, "0.10\n-0\n.1\n-00.5\n1.\n-.50\n0.09\n", "-.50\n-00.5\n-0\n0.09\n.1\n0.10\n1.\n"},
+		{"numeric beyond float64", []string{"-k2n"}, "a 9007199254740993\nb 9007199254740992\nc -9007199254740993\nd -9007199254740992\n", "c -9007199254740993\nd -9007199254740992\nb 9007199254740992\na 9007199254740993\n"},
+		{"numeric unique beyond float64", []string{"-nu"}, "9007199254740993\n9007199254740992\n09007199254740992.0\n", "9007199254740992\n9007199254740993\n"},
+		{"key", []string{"-k", "2"}, "x b\ny a\nz c\n", "y a\nx b\nz c\n"},
+		{"key attached", []string{"-k2,2n"}, "x 10 a\ny 9 b\nz 100 c\n", "y 9 b\nx 10 a\nz 100 c\n"},
+		{"key characters", []string{"-k", "1.2,1.3"}, "xca\nyab\nzbc\n", "yab\nzbc\nxca\n"},
+		{"multiple keys", []string{"-k", "2,2", "-k", "1,1r"}, "a 1\nb 2\nc 1\n", "c 1\na 1\nb 2\n"},
+		{"key options override", []string{"-r", "-k", "2,2n", "-k", "1,1"}, "a 2\nb 10\nc 2\n", "c 2\na 2\nb 10\n"},
+		{"separator", []string{"-t", ":", "-k", "2"}, "a:c\nb:a\nc:b\n", "b:a\nc:b\na:c\n"},
+		{"separator empty fields", []string{"-t,", "-k3,3"}, "a,,c\nb,x,a\nc\n", "c\nb,x,a\na,,c\n"},
+		{"blank fields", []string{"-k2,2"}, "a  b\nb c\n", "a  b\nb c\n"},
+		{"missing field", []string{"-k", "3"}, "a b c\nd\n", "d\na b c\n"},
+		{"unique", []string{"-u"}, "b\na\nb\na\n", "a\nb\n"},
+		{"unique key", []string{"-u", "-k", "1,1"}, "a 2\nb 1\na 1\n", "a 2\nb 1\n"},
+		{"last resort", []string{"-k", "1,1"}, "a 2\nb 1\na 1\n", "a 1\na 2\nb 1\n"},
+		{"stable", []string{"-s", "-k", "1,1"}, "a 2\nb 1\na 1\n", "a 2\na 1\nb 1\n"},
+		{"stable reverse", []string{"-s", "-r", "-k", "1,1"}, "a 2\nb 1\na 1\n", "b 1\na 2\na 1\n"},
+		{"end of flags", []string{"--"}, "b\na\n", "a\nb\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, stderr, status := runTest(t, tt.Args, tt.Input)
+			if status != 0 || got != tt.Want {
+				t.Errorf("gosort %v returned %d %q with output %q, expected %q", tt.Args, status, stderr, got, tt.Want)
+			}
+		})
+	}
+}
+
+func TestRunFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	a := write("a", "1\n4\n7\n")
+	b := write("b", "2\n5\n8\n")
+	c := write("c", "3\n6\n9")
+	unsorted := write("unsorted", "1\n3\n2\n")
+
+	if got, stderr, status := runTest(t, []string{"-m", "-n", a, b, c}, ""); status != 0 || got != "1\n2\n3\n4\n5\n6\n7\n8\n9\n" {
+		t.Errorf("merging returned %d %q with output %q", status, stderr, got)
+	}
+	if got, stderr, status := runTest(t, []string{c, "-", a}, "0\n"); status != 0 || got != "0\n1\n3\n4\n6\n7\n9\n" {
+		t.Errorf("sorting multiple files returned %d %q with output %q", status, stderr, got)
+	}
+	if _, stderr, status := runTest(t, []string{"-o", unsorted, unsorted}, ""); status != 0 {
+		t.Errorf("sorting in place returned %d %q", status, stderr)
+	}
+	if got, _ := os.ReadFile(unsorted); string(got) != "1\n2\n3\n" {
+		t.Errorf("sorting in place resulted in %q", got)
+	}
+
+	checks := []struct {
+		Args   []string
+		Input  string
+		Status int
+		Stderr string
+	}{
+		{[]string{"-c"}, "a\nb\nb\n", 0, ""},
+		{[]string{"-c"}, "a\nc\nb\n", 1, "gosort: -:3: disorder: b\n"},
+		{[]string{"-c", "-u"}, "a\nb\nb\n", 1, "gosort: -:3: disorder: b\n"},
+		{[]string{"-c", "-n", "-r"}, "10\n9\n", 0, ""},
+		{[]string{"-c", a}, "", 0, ""},
+		{[]string{"-c", a, b}, "", 2, "gosort: -c accepts only a single file\n"},
+	}
+	for _, tt := range checks {
+		if _, stderr, status := runTest(t, tt.Args, tt.Input); status != tt.Status || stderr != tt.Stderr {
+			t.Errorf("gosort %v returned %d %q, expected %d %q", tt.Args, status, stderr, tt.Status, tt.Stderr)
+		}
+	}
+
+	errors := [][]string{
+		{filepath.Join(dir, "missing")},
+		{"-k", "0"},
+		{"-k", "1.0"},
+		{"-k", "x"},
+		{"-k", "1,0"},
+		{"-t", "ab"},
+		{"-S", "lots"},
+		{"-x"},
+		{"--unknown"},
+	}
+	for _, args := range errors {
+		if _, _, status := runTest(t, args, ""); status != 2 {
+			t.Errorf("gosort %v returned %d, expected 2", args, status)
+		}
+	}
+}
+
+func TestRunExternal(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	var input strings.Builder
+	var lines []string
+	for i := range 20000 {
+		line := fmt.Sprintf("%d\t%x\t%d", random.IntN(1000), random.Uint32(), i)
+		lines = append(lines, line)
+		input.WriteString(line + "\n")
+	}
+	slices.SortStableFunc(lines, func(a, b string) int {
+		var ka, kb int
+		fmt.Sscan(a, &ka)
+		fmt.Sscan(b, &kb)
+		return ka - kb
+	})
+	want := strings.Join(lines, "\n") + "\n"
+
+	dir := t.TempDir()
+	got, stderr, status := runTest(t, []string{"-S", "16K", "-T", dir, "-s", "-n", "-k", "1,1"}, input.String())
+	if status != 0 || got != want {
+		t.Errorf("sorting externally returned %d %q with a different output than sorting stably in memory", status, stderr)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("sorting externally left %d temporary files", len(entries))
+	}
+}
+
+func TestRunRecords(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	var data []byte
+	type record struct {
+		Key   int16
+		Index uint32
+	}
+	var want []record
+	for i := range 10000 {
+		r := record{int16(random.IntN(200) - 100), uint32(i)}
+		want = append(want, r)
+		data = binary.LittleEndian.AppendUint16(data, uint16(r.Key))
+		data = binary.BigEndian.AppendUint32(data, r.Index)
+	}
+	slices.SortStableFunc(want, func(a, b record) int { return cmp.Compare(b.Key, a.Key) })
+	var wantData []byte
+	for _, r := range want {
+		wantData = binary.LittleEndian.AppendUint16(wantData, uint16(r.Key))
+		wantData = binary.BigEndian.AppendUint32(wantData, r.Index)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "records")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, stderr, status := runTest(t, []string{"-R", "6", "-k", "0:2:sl", "-c", path}, ""); status != 1 || stderr == "" {
+		t.Errorf("checking unsorted records returned %d %q", status, stderr)
+	}
+	got, stderr, status := runTest(t, []string{"-R", "6", "-k", "0:2:slr"}, string(data))
+	if status != 0 || got != string(wantData) {
+		t.Errorf("sorting records returned %d %q with unexpected output", status, stderr)
+	}
+	tempDir := t.TempDir()
+	if _, stderr, status := runTest(t, []string{"-R6", "-r", "-k0:2:sl", "-S", "8K", "-T", tempDir, "-o", path, path}, ""); status != 0 {
+		t.Errorf("sorting records externally in place returned %d %q", status, stderr)
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, wantData) {
+		t.Error("sorting records externally in place resulted in unexpected output")
+	}
+	if _, stderr, status := runTest(t, []string{"-R", "6", "-k", "0:2:slr", "-c", path}, ""); status != 0 {
+		t.Errorf("checking sorted records returned %d %q", status, stderr)
+	}
+	if got, _, status := runTest(t, []string{"-R", "2"}, "\x02\x01\x01\x02\x01\x01"); status != 0 || got != "\x01\x01\x01\x02\x02\x01" {
+		t.Errorf("sorting records by all bytes returned %d with output %q", status, got)
+	}
+
+	for _, args := range [][]string{
+		{"-R", "6", "-k", "4:4"},
+		{"-R", "6", "-k", "1.2"},
+		{"-R", "6", "-n"},
+		{"-R", "-1"},
+		{"-R", "4"},
+	} {
+		if _, _, status := runTest(t, args, "12345"); status != 2 {
+			t.Errorf("gosort %v returned %d, expected 2", args, status)
+		}
+	}
+}
+
+func TestExpandFlags(t *testing.T) {
+	tests := []struct {
+		Args []string
+		Want []string
+	}{
+		{[]string{"-nr", "a"}, []string{"-n", "-r", "a"}},
+		{[]string{"-nk2,2", "-t", "-", "-"}, []string{"-n", "-k", "2,2", "-t", "-", "-"}},
+		{[]string{"-R=4", "-so", "out"}, []string{"-R=4", "-s", "-o", "out"}},
+		{[]string{"--help", "-nr"}, []string{"--help", "-n", "-r"}},
+		{[]string{"--k", "2", "--r", "-nr"}, []string{"--k", "2", "--r", "-n", "-r"}},
+		{[]string{"--k=2", "a", "-r"}, []string{"--k=2", "a", "-r"}},
+		{[]string{"--", "-nr"}, []string{"--", "-nr"}},
+	}
+	for _, tt := range tests {
+		if got := expandFlags(tt.Args); !slices.Equal(got, tt.Want) {
+			t.Errorf("expandFlags(%q) = %q, expected %q", tt.Args, got, tt.Want)
+		}
+	}
+
+	_, stderr, status := runTest(t, []string{"--help"}, "")
+	if status != 0 || !strings.Contains(stderr, "Usage") || !strings.Contains(stderr, "-k") {
+		t.Errorf("gosort --help returned %d %q", status, stderr)
+	}
+	if got, stderr, status := runTest(t, []string{"--n", "-rs"}, "9\n10\n"); status != 0 || got != "10\n9\n" {
+		t.Errorf("gosort --n -rs returned %d %q with output %q", status, stderr, got)
+	}
+}
+
+func TestRunCSV(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Args  []string
+		Input string
+		Want  string
+	}{
+		{"all fields", []string{"-C"}, "b,1\na,2\na,1\n", "a,1\na,2\nb,1\n"},
+		{"header", []string{"-CH", "-k", "size:int:r"}, "name,size\na,9\nb,10\n", "name,size\nb,10\na,9\n"},
+		{"reverse", []string{"-C", "-r", "-k2:float", "-k1"}, "a,1.5\nb,1.5\nc,-2\n", "b,1.5\na,1.5\nc,-2\n"},
+		{"tsv", []string{"-C", "-t", "\t", "-k", "2:natural"}, "a\titem10\nb\titem9\n", "b\titem9\na\titem10\n"},
+		{"multiline", []string{"-C", "-k", "2"}, "\"x\ny\",b\nz,a\n", "z,a\n\"x\ny\",b\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, stderr, status := runTest(t, tt.Args, tt.Input)
+			if status != 0 || got != tt.Want {
+				t.Errorf("gosort %v returned %d %q with output %q, expected %q", tt.Args, status, stderr, got, tt.Want)
+			}
+		})
+	}
+
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.csv"), filepath.Join(dir, "b.csv")
+	os.WriteFile(a, []byte("n\n3\n1"), 0o644)
+	os.WriteFile(b, []byte("n\n2\n"), 0o644)
+	if _, stderr, status := runTest(t, []string{"-C", "-H", "-k", "n:int", "-o", a, a, b}, ""); status != 0 {
+		t.Errorf("sorting multiple CSV files returned %d %q", status, stderr)
+	}
+	if got, _ := os.ReadFile(a); string(got) != "n\n1\n2\n3\n" {
+		t.Errorf("sorting multiple CSV files resulted in %q", got)
+	}
+
+	for _, args := range [][]string{
+		{"-H"},
+		{"-C", "-n"},
+		{"-C", "-c"},
+		{"-C", "-R", "4"},
+		{"-C", "-S", "1M"},
+		{"-C", "-T", "."},
+		{"-C", "-r"},
+		{"-C", "-k", "1:number"},
+		{"-C", "-k", "x"},
+	} {
+		if _, _, status := runTest(t, args, "a\n"); status != 2 {
+			t.Errorf("gosort %v returned %d, expected 2", args, status)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fossoreslp/go-sort/extsort"
+	"github.com/fossoreslp/go-sort/records"
+)
+
+// recordConfig holds the options for sorting binary records.
+type recordConfig struct {
+	size    int
+	reverse bool
+	check   bool
+	output  string
+}
+
+// run sorts or checks the records of the files, returning the exit status.
+// Without keys, records are ordered by all of their bytes.
+func (r recordConfig) run(keyDefs []string, files []string, opts extsort.Options[[]byte], stdin io.Reader, stdout, stderr io.Writer) int {
+	fail := func(err error) int {
+		fmt.Fprintln(stderr, "gosort:", err)
+		return 2
+	}
+	if r.size < 0 {
+		return fail(errors.New("invalid record size"))
+	}
+	keys := []records.Key{{Offset: 0, Length: r.size}}
+	if len(keyDefs) > 0 {
+		keys = keys[:0]
+	}
+	for _, s := range keyDefs {
+		k, err := records.ParseKey(s)
+		if err != nil {
+			return fail(err)
+		}
+		keys = append(keys, k)
+	}
+	if r.reverse {
+		for i := range keys {
+			keys[i].Descending = !keys[i].Descending
+		}
+	}
+
+	in, err := openInputs(files, stdin)
+	if err != nil {
+		return fail(err)
+	}
+	defer in.Close()
+
+	if r.check {
+		return r.checkSorted(files[0], in, keys, stderr)
+	}
+	out := newOutput(r.output, stdout)
+	defer out.Close()
+	if err := records.SortStream(out, in, r.size, keys, opts); err != nil {
+		return fail(err)
+	}
+	if err := out.Close(); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+// checkSorted reports the first record of in that is out of order, returning the exit status.
+func (r recordConfig) checkSorted(name string, in io.Reader, keys []records.Key, stderr io.Writer) int {
+	compare := records.Compare(keys)
+	br := bufio.NewReader(in)
+	previous, current := make([]byte, r.size), make([]byte, r.size)
+	for number := 1; ; number++ {
+		if _, err := io.ReadFull(br, current); err != nil {
+			if err == io.EOF {
+				return 0
+			}
+			fmt.Fprintln(stderr, "gosort:", err)
+			return 2
+		}
+		if number > 1 && compare(previous, current) > 0 {
+			fmt.Fprintf(stderr, "gosort: %s: disorder: record %d\n", name, number)
+			return 1
+		}
+		previous, current = current, previous
+	}
+}
+
+// inputs concatenates the named files, which are read from stdin if the name is "-".
+type inputs struct {
+	io.Reader
+	// readers are the individual files, for formats that cannot simply be concatenated
+	readers []io.Reader
+	files   []*os.File
+}
+
+// openInputs opens all named files, returning a reader concatenating them.
+func openInputs(names []string, stdin io.Reader) (*inputs, error) {
+	in := &inputs{}
+	readers := make([]io.Reader, len(names))
+	for i, name := range names {
+		if name == "-" {
+			readers[i] = stdin
+			continue
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			in.Close()
+			return nil, err
+		}
+		in.files = append(in.files, f)
+		readers[i] = f
+	}
+	in.Reader, in.readers = io.MultiReader(readers...), readers
+	return in, nil
+}
+
+// Close closes all files.
+func (in *inputs) Close() error {
+	var errs []error
+	for _, f := range in.files {
+		errs = append(errs, f.Close())
+	}
+	return errors.Join(errs...)
+}
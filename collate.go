@@ -0,0 +1,133 @@
+package sort
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unicode/utf8"
+)
+
+// Collator maps strings to sort keys, which order the strings according to the rules of the collator when compared using bytes.Compare.
+// It allows sorting strings in the order expected for a language without this package depending on a specific implementation.
+// A collator from golang.org/x/text/collate can be used by wrapping its KeyFromString method.
+type Collator interface {
+	// AppendKey appends the sort key for s to dst and returns the result.
+	AppendKey(dst []byte, s string) []byte
+}
+
+// CollatorFunc is an adapter allowing to use an ordinary function as a Collator.
+type CollatorFunc func(dst []byte, s string) []byte
+
+// AppendKey calls f(dst, s).
+func (f CollatorFunc) AppendKey(dst []byte, s string) []byte {
+	return f(dst, s)
+}
+
+// CollateCompare compares two strings according to the rules of the collator c.
+// It computes both keys for every call, so CollateSort should be used for sorting.
+func CollateCompare(c Collator, a, b string) int {
+	return bytes.Compare(c.AppendKey(nil, a), c.AppendKey(nil, b))
+}
+
+// CollateSort sorts strings according to the rules of the collator c.
+// The key for every string is computed only once and the keys are sorted using radix sort.
+// The sort is stable, so strings with identical keys retain their original order.
+func CollateSort(items []string, c Collator) []string {
+	keys := make([]string, len(items))
+	var buf []byte
+	for i, s := range items {
+		buf = c.AppendKey(buf[:0], s)
+		keys[i] = string(buf)
+	}
+	radixSortStringPairs(keys, items)
+	return items
+}
+
+// Tailoring describes the rules of a TailoredCollator for a language.
+// All strings are case folded before they are compared, so rules only have to be given for one case.
+type Tailoring struct {
+	// Alphabet lists the letters of the language in their collation order.
+	// Entries can consist of multiple characters, which are then ordered as a single letter, such as "ch" in Czech.
+	// Characters that are not part of the alphabet are ordered by their code point, with those before the first letter of the alphabet being ordered before all letters.
+	Alphabet []string
+	// Expansions map strings to the strings they are ordered as, such as "ß" to "ss" in German.
+	// They can also be used to make accented letters equal to their base letter.
+	Expansions map[string]string
+}
+
+// TailoredCollator is a Collator following the rules described by a Tailoring.
+// Strings that are equal according to the rules are ordered by their bytes, so the resulting order is always the same.
+type TailoredCollator struct {
+	weights map[string][]uint32
+	// maxLen is the length in bytes of the longest entry in weights
+	maxLen int
+	// anchor is the code point of the first letter of the alphabet, all code points from it onwards are shifted to make room for the alphabet
+	anchor rune
+	size   uint32
+}
+
+// NewTailoredCollator creates a collator following the rules described by t.
+func NewTailoredCollator(t Tailoring) *TailoredCollator {
+	c := &TailoredCollator{weights: map[string][]uint32{}, anchor: utf8.MaxRune + 1, size: uint32(len(t.Alphabet))}
+	for _, letter := range t.Alphabet {
+		if r, _ := utf8.DecodeRuneInString(string(appendFolded(nil, letter))); r < c.anchor {
+			c.anchor = r
+		}
+	}
+	for i, letter := range t.Alphabet {
+		c.add(letter, []uint32{uint32(c.anchor) + 1 + uint32(i)})
+	}
+	// Expansions are resolved using only the alphabet before adding them, so they cannot refer to each other
+	expansions := make(map[string][]uint32, len(t.Expansions))
+	for from, to := range t.Expansions {
+		expansions[from] = c.appendWeights(nil, string(appendFolded(nil, to)))
+	}
+	for from, weights := range expansions {
+		c.add(from, weights)
+	}
+	return c
+}
+
+// add registers the weights for the case folded form of s.
+func (c *TailoredCollator) add(s string, weights []uint32) {
+	folded := string(appendFolded(nil, s))
+	c.weights[folded] = weights
+	c.maxLen = max(c.maxLen, len(folded))
+}
+
+// appendWeights appends the weights of the already case folded string s, matching the longest entries first.
+func (c *TailoredCollator) appendWeights(dst []uint32, s string) []uint32 {
+	for len(s) > 0 {
+		matched := false
+		for l := min(c.maxLen, len(s)); l > 0; l-- {
+			if w, ok := c.weights[s[:l]]; ok {
+				dst = append(dst, w...)
+				s = s[l:]
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		// Weight zero is reserved for terminating the weights in the key
+		w := uint32(r) + 1
+		if r >= c.anchor {
+			w += c.size
+		}
+		dst = append(dst, w)
+	}
+	return dst
+}
+
+// AppendKey appends the sort key for s to dst.
+// The key consists of the weights of the case folded string followed by the string itself to order strings that are otherwise equal.
+func (c *TailoredCollator) AppendKey(dst []byte, s string) []byte {
+	var buf [32]uint32
+	for _, w := range c.appendWeights(buf[:0], string(appendFolded(nil, s))) {
+		dst = binary.BigEndian.AppendUint32(dst, w)
+	}
+	dst = binary.BigEndian.AppendUint32(dst, 0)
+	return append(dst, s...)
+}
@@ -1,15 +1,23 @@
 package sort
 
 import (
+	"bytes"
 	"cmp"
+	"context"
 	crand "crypto/rand"
+	"encoding/json"
+	"errors"
 	"io"
+	"maps"
 	"math"
+	"math/big"
 	"math/rand/v2"
+	"net/netip"
 	"reflect"
 	"slices"
 	"strings"
 	"testing"
+	"time"
 	"unsafe"
 )
 
@@ -158,27 +166,24 @@ func cmpFloatSlice[T float32 | float64](a []T, b []T) bool {
 func testFloat[T float32 | float64](t *testing.T, name string, fn func([]T) []T) {
 	t.Run(name, func(t *testing.T) {
 		tests := []struct {
-			Name    string
-			Input   []T
-			Want    []T
-			CanFail bool
+			Name  string
+			Input []T
+			Want  []T
 		}{
-			{"small values", []T{1, 0.1, 3, 0.01}, []T{0.01, 0.1, 1, 3}, false},
-			{"large values", []T{100, 1, 123456789, 10}, []T{1, 10, 100, 123456789}, false},
-			{"negative values", []T{-1, -2, -3, -4, -5}, []T{-5, -4, -3, -2, -1}, false},
-			{"mixed values", []T{-1, 0, 1.2, 123456789, -2, 2, -3.5, 3}, []T{-3.5, -2, -1, 0, 1.2, 2, 3, 123456789}, false},
-			{"infinity", []T{2, T(math.Inf(1)), 1, T(math.Inf(-1))}, []T{T(math.Inf(-1)), 1, 2, T(math.Inf(1))}, false},
-			{"NaN", []T{12345, T(math.NaN()), 100, T(math.NaN()), -5.5, T(math.NaN()), T(math.NaN())}, []T{T(math.NaN()), T(math.NaN()), T(math.NaN()), T(math.NaN()), -5.5, 100, 12345}, true},
+			{"small values", []T{1, 0.1, 3, 0.01}, []T{0.01, 0.1, 1, 3}},
+			{"large values", []T{100, 1, 123456789, 10}, []T{1, 10, 100, 123456789}},
+			{"negative values", []T{-1, -2, -3, -4, -5}, []T{-5, -4, -3, -2, -1}},
+			{"mixed values", []T{-1, 0, 1.2, 123456789, -2, 2, -3.5, 3}, []T{-3.5, -2, -1, 0, 1.2, 2, 3, 123456789}},
+			{"infinity", []T{2, T(math.Inf(1)), 1, T(math.Inf(-1))}, []T{T(math.Inf(-1)), 1, 2, T(math.Inf(1))}},
+			{"NaN", []T{12345, T(math.NaN()), 100, T(math.NaN()), -5.5, T(math.NaN()), T(math.NaN())}, []T{T(math.NaN()), T(math.NaN()), T(math.NaN()), T(math.NaN()), -5.5, 100, 12345}},
+			{"only NaN", []T{T(math.NaN()), T(math.NaN()), T(math.NaN())}, []T{T(math.NaN()), T(math.NaN()), T(math.NaN())}},
+			{"NaN first and last", []T{T(math.NaN()), 3, 1, 2, T(math.NaN())}, []T{T(math.NaN()), T(math.NaN()), 1, 2, 3}},
 		}
 		for _, tt := range tests {
 			t.Run(tt.Name, func(t *testing.T) {
 				fn(tt.Input)
 				if !cmpFloatSlice(tt.Input, tt.Want) {
-					if tt.CanFail {
-						t.Skipf("%s result for %s [%+v] does not match expected value [%+v]", name, tt.Name, tt.Input, tt.Want)
-					} else {
-						t.Errorf("%s result for %s [%+v] does not match expected value [%+v]", name, tt.Name, tt.Input, tt.Want)
-					}
+					t.Errorf("%s result for %s [%+v] does not match expected value [%+v]", name, tt.Name, tt.Input, tt.Want)
 				}
 			})
 		}
@@ -198,6 +203,22 @@ func testFloat[T float32 | float64](t *testing.T, name string, fn func([]T) []T)
 				t.Error(name + " does not produce the same output as slices.Sort.")
 			}
 		})
+		t.Run("1000 random with many NaNs", func(t *testing.T) {
+			values := make([]T, 1000)
+			for i := range values {
+				if random.IntN(3) == 0 {
+					values[i] = T(math.NaN())
+				} else {
+					values[i] = T(random.NormFloat64())
+				}
+			}
+			values2 := slices.Clone(values)
+			fn(values)
+			slices.Sort(values2)
+			if !cmpFloatSlice(values, values2) {
+				t.Error(name + " does not produce the same output as slices.Sort.")
+			}
+		})
 	})
 }
 
@@ -210,6 +231,8 @@ func TestSort_uint(t *testing.T) {
 		{"MergeSort", MergeSort[uint]},
 		{"QuickSort", QuickSort[uint]},
 		{"RadixSort", RadixSort[uint]},
+		{"Sort", Sort[uint]},
+		{"SortStable", SortStable[uint]},
 	}
 
 	for _, alg := range algorithms {
@@ -226,6 +249,8 @@ func TestSort_uint8(t *testing.T) {
 		{"MergeSort", MergeSort[uint8]},
 		{"QuickSort", QuickSort[uint8]},
 		{"RadixSort", RadixSort[uint8]},
+		{"Sort", Sort[uint8]},
+		{"SortStable", SortStable[uint8]},
 	}
 
 	for _, alg := range algorithms {
@@ -242,6 +267,8 @@ func TestSort_uint16(t *testing.T) {
 		{"MergeSort", MergeSort[uint16]},
 		{"QuickSort", QuickSort[uint16]},
 		{"RadixSort", RadixSort[uint16]},
+		{"Sort", Sort[uint16]},
+		{"SortStable", SortStable[uint16]},
 	}
 
 	for _, alg := range algorithms {
@@ -258,6 +285,8 @@ func TestSort_uint32(t *testing.T) {
 		{"MergeSort", MergeSort[uint32]},
 		{"QuickSort", QuickSort[uint32]},
 		{"RadixSort", RadixSort[uint32]},
+		{"Sort", Sort[uint32]},
+		{"SortStable", SortStable[uint32]},
 	}
 
 	for _, alg := range algorithms {
@@ -274,6 +303,8 @@ func TestSort_uint64(t *testing.T) {
 		{"MergeSort", MergeSort[uint64]},
 		{"QuickSort", QuickSort[uint64]},
 		{"RadixSort", RadixSort[uint64]},
+		{"Sort", Sort[uint64]},
+		{"SortStable", SortStable[uint64]},
 	}
 
 	for _, alg := range algorithms {
@@ -290,6 +321,8 @@ func TestSort_uintptr(t *testing.T) {
 		{"MergeSort", MergeSort[uintptr]},
 		{"QuickSort", QuickSort[uintptr]},
 		{"RadixSort", RadixSort[uintptr]},
+		{"Sort", Sort[uintptr]},
+		{"SortStable", SortStable[uintptr]},
 	}
 
 	for _, alg := range algorithms {
@@ -306,6 +339,8 @@ func TestSort_int(t *testing.T) {
 		{"MergeSort", MergeSort[int]},
 		{"QuickSort", QuickSort[int]},
 		{"RadixSort", RadixSort[int]},
+		{"Sort", Sort[int]},
+		{"SortStable", SortStable[int]},
 	}
 
 	for _, alg := range algorithms {
@@ -323,6 +358,8 @@ func TestSort_int8(t *testing.T) {
 		{"MergeSort", MergeSort[int8]},
 		{"QuickSort", QuickSort[int8]},
 		{"RadixSort", RadixSort[int8]},
+		{"Sort", Sort[int8]},
+		{"SortStable", SortStable[int8]},
 	}
 
 	for _, alg := range algorithms {
@@ -340,6 +377,8 @@ func TestSort_int16(t *testing.T) {
 		{"MergeSort", MergeSort[int16]},
 		{"QuickSort", QuickSort[int16]},
 		{"RadixSort", RadixSort[int16]},
+		{"Sort", Sort[int16]},
+		{"SortStable", SortStable[int16]},
 	}
 
 	for _, alg := range algorithms {
@@ -357,6 +396,8 @@ func TestSort_int32(t *testing.T) {
 		{"MergeSort", MergeSort[int32]},
 		{"QuickSort", QuickSort[int32]},
 		{"RadixSort", RadixSort[int32]},
+		{"Sort", Sort[int32]},
+		{"SortStable", SortStable[int32]},
 	}
 
 	for _, alg := range algorithms {
@@ -374,6 +415,8 @@ func TestSort_int64(t *testing.T) {
 		{"MergeSort", MergeSort[int64]},
 		{"QuickSort", QuickSort[int64]},
 		{"RadixSort", RadixSort[int64]},
+		{"Sort", Sort[int64]},
+		{"SortStable", SortStable[int64]},
 	}
 
 	for _, alg := range algorithms {
@@ -391,6 +434,8 @@ func TestSort_float32(t *testing.T) {
 		{"MergeSort", MergeSort[float32]},
 		{"QuickSort", QuickSort[float32]},
 		{"RadixSort", RadixSort[float32]},
+		{"Sort", Sort[float32]},
+		{"SortStable", SortStable[float32]},
 	}
 
 	for _, alg := range algorithms {
@@ -407,6 +452,8 @@ func TestSort_float64(t *testing.T) {
 		{"MergeSort", MergeSort[float64]},
 		{"QuickSort", QuickSort[float64]},
 		{"RadixSort", RadixSort[float64]},
+		{"Sort", Sort[float64]},
+		{"SortStable", SortStable[float64]},
 	}
 
 	for _, alg := range algorithms {
@@ -423,6 +470,8 @@ func TestSort_string(t *testing.T) {
 		{"MergeSort", MergeSort[string]},
 		{"QuickSort", QuickSort[string]},
 		{"RadixSort", RadixSort[string]},
+		{"Sort", Sort[string]},
+		{"SortStable", SortStable[string]},
 	}
 	for _, alg := range algorithms {
 		t.Run(alg.Name, func(t *testing.T) {
@@ -460,6 +509,75 @@ func TestSort_string(t *testing.T) {
 	}
 }
 
+func TestNaturalCompare(t *testing.T) {
+	tests := []struct {
+		A    string
+		B    string
+		Want int
+	}{
+		{"", "", 0},
+		{"", "a", -1},
+		{"item2", "item10", -1},
+		{"item10", "item2", 1},
+		{"item10", "item10", 0},
+		{"item", "item1", -1},
+		{"1", "a", -1},
+		{"a1", "a01", -1},
+		{"a01", "a001", -1},
+		{"a01b", "a1c", -1},
+		{"a01c", "a1b", 1},
+		{"a1b01", "a01b1", -1},
+		{"007", "7", 1},
+		{"0", "00", -1},
+		{"x18446744073709551616", "x18446744073709551615", 1},
+		{"x99999999999999999999999", "x100000000000000000000000", -1},
+		{"1.2.10", "1.2.9", 1},
+		{"file 2.txt", "file 10.txt", -1},
+		{"äpfel2", "äpfel10", -1},
+		{"über", "zebra", 1},
+		{"日本2", "日本10", -1},
+		{"日本", "日本語", -1},
+		{"a-2", "a-10", -1},
+	}
+	for _, tt := range tests {
+		if got := NaturalCompare(tt.A, tt.B); got != tt.Want {
+			t.Errorf("NaturalCompare(%q, %q) = %d, want %d", tt.A, tt.B, got, tt.Want)
+		}
+		if got := NaturalCompare(tt.B, tt.A); got != -tt.Want {
+			t.Errorf("NaturalCompare(%q, %q) = %d, want %d", tt.B, tt.A, got, -tt.Want)
+		}
+	}
+}
+
+func TestNaturalSort(t *testing.T) {
+	algorithms := []struct {
+		Name string
+		Func func([]string) []string
+	}{
+		{"NaturalSort", NaturalSort},
+		{"NaturalSortStable", NaturalSortStable},
+	}
+	want := []string{"", "a", "a1", "a01", "a2", "a2b", "a10", "a010", "b", "img1.png", "img2.png", "img12.png", "img100.png", "z"}
+	for _, alg := range algorithms {
+		for _, n := range []int{1, 5, 100} {
+			var input []string
+			for range n {
+				input = append(input, want...)
+			}
+			expected := slices.Clone(input)
+			slices.SortFunc(expected, NaturalCompare)
+			random.Shuffle(len(input), func(i, j int) { input[i], input[j] = input[j], input[i] })
+			alg.Func(input)
+			if !reflect.DeepEqual(input, expected) {
+				t.Errorf("%s result for %d copies [%q] does not match expected value [%q]", alg.Name, n, input, expected)
+			}
+		}
+		if got := alg.Func(slices.Clone(want)); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s result [%q] does not match expected value [%q]", alg.Name, got, want)
+		}
+	}
+}
+
 func TestInsertSorted(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -637,3 +755,1586 @@ func TestMergeSortedSets(t *testing.T) {
 		})
 	}
 }
+
+func testPairs[K cmp.Ordered](t *testing.T, name string, keys []K) {
+	t.Run(name, func(t *testing.T) {
+		vals := make([]int, len(keys))
+		for i := range vals {
+			vals[i] = i
+		}
+		type pair struct {
+			Key K
+			Val int
+		}
+		want := make([]pair, len(keys))
+		for i := range keys {
+			want[i] = pair{keys[i], vals[i]}
+		}
+		slices.SortStableFunc(want, func(a, b pair) int { return cmp.Compare(a.Key, b.Key) })
+
+		SortPairs(keys, vals)
+		for i := range want {
+			if keys[i] != want[i].Key || vals[i] != want[i].Val {
+				t.Fatalf("SortPairs result at index %d is (%v, %d), expected (%v, %d)", i, keys[i], vals[i], want[i].Key, want[i].Val)
+			}
+		}
+	})
+}
+
+func TestSortPairs(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		keys, vals := SortPairs([]int{}, []string{})
+		if len(keys) != 0 || len(vals) != 0 {
+			t.Errorf("SortPairs on empty slices returned (%v, %v)", keys, vals)
+		}
+	})
+	t.Run("different lengths", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("SortPairs did not panic for slices of different lengths")
+			}
+		}()
+		SortPairs([]int{1, 2}, []string{"a"})
+	})
+	t.Run("small", func(t *testing.T) {
+		keys := []int{3, 1, 2, 1, -5}
+		vals := []string{"c", "a", "b", "a2", "neg"}
+		SortPairs(keys, vals)
+		if !reflect.DeepEqual(keys, []int{-5, 1, 1, 2, 3}) || !reflect.DeepEqual(vals, []string{"neg", "a", "a2", "b", "c"}) {
+			t.Errorf("SortPairs result (%v, %v) does not match expected value", keys, vals)
+		}
+	})
+
+	uint8s := make([]uint8, 1000)
+	fillRandom(uint8s)
+	testPairs(t, "uint8", uint8s)
+	int8s := make([]int8, 1000)
+	fillRandom(int8s)
+	testPairs(t, "int8", int8s)
+	uint16s := make([]uint16, 1000)
+	fillRandom(uint16s)
+	testPairs(t, "uint16", uint16s)
+	int32s := make([]int32, 1000)
+	fillRandom(int32s)
+	testPairs(t, "int32", int32s)
+	ints := make([]int, 1000)
+	fillRandom(ints)
+	testPairs(t, "int", ints)
+	// Small values leave most bytes unused, causing passes to be skipped
+	smallInts := make([]int64, 1000)
+	for i := range smallInts {
+		smallInts[i] = random.Int64N(50) - 25
+	}
+	testPairs(t, "int64 small range", smallInts)
+	uint64s := make([]uint64, 1000)
+	for i := range uint64s {
+		uint64s[i] = random.Uint64N(1 << 24)
+	}
+	testPairs(t, "uint64 three bytes", uint64s)
+	float64s := make([]float64, 1000)
+	for i := range float64s {
+		float64s[i] = float64(random.IntN(100)) / 4
+	}
+	testPairs(t, "float64", float64s)
+	strs := make([]string, 1000)
+	for i := range strs {
+		strs[i] = randomString(random.Int64N(3))
+	}
+	testPairs(t, "string", strs)
+}
+
+func TestSort_presorted(t *testing.T) {
+	algorithms := []struct {
+		Name string
+		Func func(Thresholds, []int) []int
+	}{
+		{"SortWith", SortWith[int]},
+		{"SortStableWith", SortStableWith[int]},
+	}
+	inputs := []struct {
+		Name  string
+		Input func(n int) []int
+	}{
+		{"sorted", func(n int) []int {
+			values := make([]int, n)
+			for i := range values {
+				values[i] = i
+			}
+			return values
+		}},
+		{"reversed", func(n int) []int {
+			values := make([]int, n)
+			for i := range values {
+				values[i] = n - i
+			}
+			return values
+		}},
+		{"few runs", func(n int) []int {
+			values := make([]int, n)
+			for i := range values {
+				values[i] = (i * 7) % (n/5 + 1)
+			}
+			return values
+		}},
+		{"sorted with appended values", func(n int) []int {
+			values := make([]int, n)
+			for i := range values {
+				values[i] = i
+			}
+			return append(values, -3, 17, 5)
+		}},
+		{"nearly sorted", func(n int) []int {
+			values := make([]int, n)
+			for i := range values {
+				values[i] = i / 2
+			}
+			for i := 0; i+1 < n; i += 37 {
+				values[i], values[i+1] = values[i+1]+1, values[i]
+			}
+			return values
+		}},
+		{"random", func(n int) []int {
+			values := make([]int, n)
+			fillRandom(values)
+			return values
+		}},
+	}
+	thresholds := []struct {
+		Name       string
+		Thresholds Thresholds
+	}{
+		{"default", DefaultThresholds},
+		{"no shortcuts", Thresholds{Insertion: 0, Radix: math.MaxInt, RadixStable: math.MaxInt, Runs: 0}},
+		{"radix only", Thresholds{Insertion: 0, Radix: 0, RadixStable: 0, Runs: 0}},
+		{"many runs", Thresholds{Insertion: 0, Radix: 0, RadixStable: 0, Runs: 1000}},
+	}
+	for _, alg := range algorithms {
+		for _, th := range thresholds {
+			for _, in := range inputs {
+				for _, n := range []int{5, 100, 5000} {
+					values := in.Input(n)
+					want := slices.Clone(values)
+					slices.Sort(want)
+					alg.Func(th.Thresholds, values)
+					if !reflect.DeepEqual(values, want) {
+						t.Errorf("%s with %s thresholds does not sort %d %s items correctly", alg.Name, th.Name, n, in.Name)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSetThresholds(t *testing.T) {
+	defer SetThresholds(DefaultThresholds)
+	if CurrentThresholds() != DefaultThresholds {
+		t.Errorf("CurrentThresholds() = %+v, want %+v", CurrentThresholds(), DefaultThresholds)
+	}
+	custom := Thresholds{Insertion: 1, Radix: 2, RadixStable: 3, Runs: 4}
+	SetThresholds(custom)
+	if CurrentThresholds() != custom {
+		t.Errorf("CurrentThresholds() = %+v, want %+v", CurrentThresholds(), custom)
+	}
+}
+
+func TestCalibrate(t *testing.T) {
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if got := Calibrate(ctx, time.Second); got != DefaultThresholds {
+			t.Errorf("Calibrate() with cancelled context = %+v, want %+v", got, DefaultThresholds)
+		}
+	})
+	t.Run("measure", func(t *testing.T) {
+		// The time taken is not checked since it depends on the load of the machine running the tests
+		got := Calibrate(context.Background(), 100*time.Millisecond)
+		if got.Insertion < 0 || got.Insertion > calibrateInsertionSizes[len(calibrateInsertionSizes)-1] {
+			t.Errorf("Calibrate() returned insertion threshold %d outside of the measured sizes", got.Insertion)
+		}
+		if got.Radix != math.MaxInt && !slices.Contains(calibrateRadixSizes, got.Radix) {
+			t.Errorf("Calibrate() returned radix threshold %d outside of the measured sizes", got.Radix)
+		}
+		if got.RadixStable != math.MaxInt && !slices.Contains(calibrateRadixSizes, got.RadixStable) {
+			t.Errorf("Calibrate() returned stable radix threshold %d outside of the measured sizes", got.RadixStable)
+		}
+		if got.Insertion > got.Radix || got.Insertion > got.RadixStable {
+			t.Errorf("Calibrate() returned insertion threshold %d above radix thresholds %d and %d", got.Insertion, got.Radix, got.RadixStable)
+		}
+		if got.Runs != DefaultThresholds.Runs {
+			t.Errorf("Calibrate() changed runs threshold to %d", got.Runs)
+		}
+	})
+	t.Run("json", func(t *testing.T) {
+		data, err := json.Marshal(DefaultThresholds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Thresholds
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != DefaultThresholds {
+			t.Errorf("JSON round trip of %s resulted in %+v", data, got)
+		}
+	})
+}
+
+func TestSortCtx(t *testing.T) {
+	algorithms := []struct {
+		Name string
+		Func func(context.Context, []int64) error
+	}{
+		{"MergeSortCtx", MergeSortCtx[int64]},
+		{"RadixSortCtx", RadixSortCtx[int64]},
+	}
+	for _, alg := range algorithms {
+		t.Run(alg.Name, func(t *testing.T) {
+			t.Run("complete", func(t *testing.T) {
+				values := make([]int64, 10000)
+				fillRandom(values)
+				want := slices.Clone(values)
+				slices.Sort(want)
+				if err := alg.Func(context.Background(), values); err != nil {
+					t.Fatalf("%s returned unexpected error: %v", alg.Name, err)
+				}
+				if !reflect.DeepEqual(values, want) {
+					t.Errorf("%s does not produce the same output as slices.Sort.", alg.Name)
+				}
+			})
+			t.Run("cancelled before start", func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				values := []int64{3, 1, 2}
+				if err := alg.Func(ctx, values); err != context.Canceled {
+					t.Errorf("%s returned %v, want %v", alg.Name, err, context.Canceled)
+				}
+				if !reflect.DeepEqual(values, []int64{3, 1, 2}) {
+					t.Errorf("%s modified the input to %v despite being cancelled", alg.Name, values)
+				}
+			})
+			t.Run("cancelled while sorting", func(t *testing.T) {
+				values := make([]int64, 1<<19)
+				fillRandom(values)
+				want := slices.Clone(values)
+				slices.Sort(want)
+				for _, delay := range []time.Duration{0, 100 * time.Microsecond, time.Millisecond} {
+					data := slices.Clone(values)
+					ctx, cancel := context.WithCancel(context.Background())
+					time.AfterFunc(delay, cancel)
+					err := alg.Func(ctx, data)
+					cancel()
+					if err != nil && err != context.Canceled {
+						t.Fatalf("%s returned unexpected error: %v", alg.Name, err)
+					}
+					// The elements must be unchanged in any case, which is checked by sorting them
+					slices.Sort(data)
+					if !reflect.DeepEqual(data, want) {
+						t.Fatalf("%s lost elements when cancelled after %v", alg.Name, delay)
+					}
+				}
+			})
+		})
+	}
+	t.Run("RadixSortCtx fallback", func(t *testing.T) {
+		values := []string{"c", "a", "b"}
+		if err := RadixSortCtx(context.Background(), values); err != nil || !reflect.DeepEqual(values, []string{"a", "b", "c"}) {
+			t.Errorf("RadixSortCtx on strings returned %v and %v", err, values)
+		}
+	})
+}
+
+func TestWithProgress(t *testing.T) {
+	algorithms := []struct {
+		Name    string
+		Func    func(context.Context, []int64) error
+		Reports int
+	}{
+		{"MergeSortCtx", MergeSortCtx[int64], 0},
+		{"RadixSortCtx", RadixSortCtx[int64], 8},
+	}
+	for _, alg := range algorithms {
+		t.Run(alg.Name, func(t *testing.T) {
+			values := make([]int64, 100000)
+			fillRandom(values)
+			var reports []float64
+			ctx := WithProgress(context.Background(), func(fraction float64) {
+				reports = append(reports, fraction)
+			})
+			if err := alg.Func(ctx, values); err != nil {
+				t.Fatalf("%s returned unexpected error: %v", alg.Name, err)
+			}
+			if !slices.IsSorted(values) {
+				t.Errorf("%s did not sort the values", alg.Name)
+			}
+			if len(reports) < 2 || len(reports) > 101 {
+				t.Fatalf("%s reported progress %d times", alg.Name, len(reports))
+			}
+			if alg.Reports != 0 && len(reports) != alg.Reports {
+				t.Errorf("%s reported progress %d times, want %d", alg.Name, len(reports), alg.Reports)
+			}
+			if !slices.IsSorted(reports) || reports[0] <= 0 || reports[len(reports)-1] != 1 {
+				t.Errorf("%s reported invalid progress %v", alg.Name, reports)
+			}
+		})
+	}
+}
+
+func testDesc[T cmp.Ordered](t *testing.T, name string, fn func([]T) []T, values []T) {
+	t.Run(name, func(t *testing.T) {
+		want := slices.Clone(values)
+		slices.Sort(want)
+		slices.Reverse(want)
+		fn(values)
+		if !reflect.DeepEqual(values, want) {
+			t.Errorf("%s result [%+v] does not match expected value [%+v]", name, values, want)
+		}
+	})
+}
+
+func TestSortDesc(t *testing.T) {
+	t.Run("RadixSortDesc", func(t *testing.T) {
+		testDesc(t, "nil", RadixSortDesc[int], nil)
+		testDesc(t, "extreme values", RadixSortDesc[int], []int{0, maxInt[int](), -1, minInt[int](), 1})
+		testDesc(t, "extreme unsigned values", RadixSortDesc[uint32], []uint32{0, maxInt[uint32](), 1, 5})
+		testDesc(t, "strings", RadixSortDesc[string], []string{"b", "", "c", "a"})
+		uint8s := make([]uint8, 1000)
+		fillRandom(uint8s)
+		testDesc(t, "uint8", RadixSortDesc[uint8], uint8s)
+		int8s := make([]int8, 1000)
+		fillRandom(int8s)
+		testDesc(t, "int8", RadixSortDesc[int8], int8s)
+		int16s := make([]int16, 1000)
+		fillRandom(int16s)
+		testDesc(t, "int16", RadixSortDesc[int16], int16s)
+		int32s := make([]int32, 1000)
+		fillRandom(int32s)
+		testDesc(t, "int32", RadixSortDesc[int32], int32s)
+		int64s := make([]int64, 1000)
+		fillRandom(int64s)
+		testDesc(t, "int64", RadixSortDesc[int64], int64s)
+		uint64s := make([]uint64, 1000)
+		fillRandom(uint64s)
+		testDesc(t, "uint64", RadixSortDesc[uint64], uint64s)
+		uintptrs := make([]uintptr, 1000)
+		fillRandom(uintptrs)
+		testDesc(t, "uintptr", RadixSortDesc[uintptr], uintptrs)
+	})
+	t.Run("MergeSortDesc", func(t *testing.T) {
+		testDesc(t, "nil", MergeSortDesc[int], nil)
+		testDesc(t, "ten elements double", MergeSortDesc[int], []int{2, 2, 8, 8, 4, 4, 6, 6, 0, 0})
+		int64s := make([]int64, 1000)
+		fillRandom(int64s)
+		testDesc(t, "int64", MergeSortDesc[int64], int64s)
+		strs := make([]string, 1000)
+		for i := range strs {
+			strs[i] = randomString(random.Int64N(10))
+		}
+		testDesc(t, "string", MergeSortDesc[string], strs)
+
+		// Positive and negative zero are equal but distinguishable, allowing to check stability
+		values := []float64{0, 1, math.Copysign(0, -1), 2, 0, math.Copysign(0, -1)}
+		MergeSortDesc(values)
+		signs := []bool{}
+		for _, v := range values[2:] {
+			signs = append(signs, math.Signbit(v))
+		}
+		if values[0] != 2 || values[1] != 1 || !reflect.DeepEqual(signs, []bool{false, true, false, true}) {
+			t.Errorf("MergeSortDesc result %v with sign bits %v is not stable", values, signs)
+		}
+	})
+}
+
+type testRow struct {
+	Tenant    string
+	Shard     uint8
+	Timestamp int64
+	ID        int
+	Index     int
+}
+
+func randomRows(n int) []testRow {
+	rows := make([]testRow, n)
+	for i := range rows {
+		rows[i] = testRow{
+			Tenant:    string(rune('a' + random.IntN(3))),
+			Shard:     uint8(random.IntN(4)),
+			Timestamp: random.Int64N(20) - 10,
+			ID:        random.IntN(5),
+			Index:     i,
+		}
+	}
+	return rows
+}
+
+func TestMergeSortFunc(t *testing.T) {
+	rows := randomRows(1000)
+	want := slices.Clone(rows)
+	slices.SortStableFunc(want, func(a, b testRow) int { return cmp.Compare(a.Timestamp, b.Timestamp) })
+	MergeSortFunc(rows, func(a, b testRow) int { return cmp.Compare(a.Timestamp, b.Timestamp) })
+	if !reflect.DeepEqual(rows, want) {
+		t.Error("MergeSortFunc does not produce the same output as slices.SortStableFunc.")
+	}
+}
+
+func TestSortFunc(t *testing.T) {
+	algorithms := []struct {
+		Name   string
+		Func   func([]testRow, func(a, b testRow) int) []testRow
+		Stable bool
+	}{
+		{"InsertionSortFunc", InsertionSortFunc[testRow], true},
+		{"MergeSortFunc", MergeSortFunc[testRow], true},
+		{"QuickSortFunc", QuickSortFunc[testRow], false},
+		{"SortFunc", SortFunc[testRow], false},
+		{"SortStableFunc", SortStableFunc[testRow], true},
+	}
+	byTimestamp := func(a, b testRow) int { return cmp.Compare(a.Timestamp, b.Timestamp) }
+	for _, alg := range algorithms {
+		for _, n := range []int{0, 1, 10, 1000} {
+			rows := randomRows(n)
+			want := slices.Clone(rows)
+			slices.SortStableFunc(want, byTimestamp)
+			alg.Func(rows, byTimestamp)
+			if alg.Stable && !reflect.DeepEqual(rows, want) {
+				t.Errorf("%s does not produce the same output as slices.SortStableFunc for %d rows.", alg.Name, n)
+			}
+			if !alg.Stable && !slices.IsSortedFunc(rows, byTimestamp) {
+				t.Errorf("%s does not sort %d rows.", alg.Name, n)
+			}
+		}
+	}
+}
+
+func TestSortByKeys(t *testing.T) {
+	tests := []struct {
+		Name string
+		Keys []Key[testRow]
+		Cmp  func(a, b testRow) int
+	}{
+		{
+			"no keys",
+			nil,
+			func(a, b testRow) int { return 0 },
+		},
+		{
+			"mixed keys",
+			[]Key[testRow]{By(func(r testRow) string { return r.Tenant }), ByDesc(func(r testRow) int64 { return r.Timestamp }), By(func(r testRow) int { return r.ID })},
+			func(a, b testRow) int {
+				return cmp.Or(cmp.Compare(a.Tenant, b.Tenant), cmp.Compare(b.Timestamp, a.Timestamp), cmp.Compare(a.ID, b.ID))
+			},
+		},
+		{
+			"integer keys",
+			[]Key[testRow]{ByDesc(func(r testRow) uint8 { return r.Shard }), By(func(r testRow) int64 { return r.Timestamp }), ByDesc(func(r testRow) int { return r.ID })},
+			func(a, b testRow) int {
+				return cmp.Or(cmp.Compare(b.Shard, a.Shard), cmp.Compare(a.Timestamp, b.Timestamp), cmp.Compare(b.ID, a.ID))
+			},
+		},
+		{
+			"single integer key",
+			[]Key[testRow]{By(func(r testRow) int64 { return r.Timestamp })},
+			func(a, b testRow) int { return cmp.Compare(a.Timestamp, b.Timestamp) },
+		},
+	}
+	for _, tt := range tests {
+		for _, n := range []int{10, 1000} {
+			rows := randomRows(n)
+			want := slices.Clone(rows)
+			slices.SortStableFunc(want, tt.Cmp)
+			SortByKeys(rows, tt.Keys...)
+			if !reflect.DeepEqual(rows, want) {
+				t.Errorf("SortByKeys with %s does not produce the same output as slices.SortStableFunc for %d rows.", tt.Name, n)
+			}
+		}
+	}
+}
+
+func TestRadixSortStringPairs(t *testing.T) {
+	for _, n := range []int{0, 10, 1000, 10000} {
+		keys := make([]string, n)
+		for i := range keys {
+			// Short strings from a small alphabet create many equal keys and shared prefixes
+			b := make([]byte, random.IntN(6))
+			for j := range b {
+				b[j] = "\x00ab\xff"[random.IntN(4)]
+			}
+			keys[i] = string(b)
+		}
+		testPairsFunc(t, n, keys, radixSortStringPairs[int])
+	}
+}
+
+func testPairsFunc(t *testing.T, n int, keys []string, fn func([]string, []int)) {
+	vals := make([]int, len(keys))
+	for i := range vals {
+		vals[i] = i
+	}
+	want := slices.Clone(vals)
+	slices.SortStableFunc(want, func(a, b int) int { return strings.Compare(keys[a], keys[b]) })
+	wantKeys := make([]string, len(keys))
+	for i, v := range want {
+		wantKeys[i] = keys[v]
+	}
+	fn(keys, vals)
+	if !reflect.DeepEqual(vals, want) || !reflect.DeepEqual(keys, wantKeys) {
+		t.Errorf("sorting %d pairs does not produce the same output as slices.SortStableFunc.", n)
+	}
+}
+
+// semVerPrecedence is ordered according to the examples in the Semantic Versioning 2.0.0 specification.
+var semVerPrecedence = []string{
+	"0.0.0-0",
+	"0.0.0",
+	"0.9.9",
+	"1.0.0-0.3.7",
+	"1.0.0-alpha",
+	"1.0.0-alpha.1",
+	"1.0.0-alpha.beta",
+	"1.0.0-beta",
+	"1.0.0-beta.2",
+	"1.0.0-beta.11",
+	"1.0.0-rc.1",
+	"1.0.0-rc.1.0",
+	"1.0.0-x-y-z.--",
+	"1.0.0",
+	"1.0.1",
+	"1.9.0",
+	"1.10.0",
+	"1.11.0",
+	"2.0.0",
+	"2.1.0",
+	"2.1.1",
+	"18446744073709551615.0.0",
+}
+
+func TestParseSemVer(t *testing.T) {
+	valid := []struct {
+		Version string
+		Want    SemVer
+	}{
+		{"1.2.3", SemVer{Major: 1, Minor: 2, Patch: 3}},
+		{"1.0.0-alpha.1", SemVer{Major: 1, Prerelease: []string{"alpha", "1"}}},
+		{"1.0.0+20130313144700", SemVer{Major: 1, Build: []string{"20130313144700"}}},
+		{"1.0.0-beta+exp.sha.5114f85", SemVer{Major: 1, Prerelease: []string{"beta"}, Build: []string{"exp", "sha", "5114f85"}}},
+		{"1.0.0-x-y-z.--+001", SemVer{Major: 1, Prerelease: []string{"x-y-z", "--"}, Build: []string{"001"}}},
+	}
+	for _, tt := range valid {
+		got, err := ParseSemVer(tt.Version)
+		if err != nil || !reflect.DeepEqual(got, tt.Want) {
+			t.Errorf("ParseSemVer(%q) = %+v, %v, want %+v", tt.Version, got, err, tt.Want)
+		}
+		if got.String() != tt.Version {
+			t.Errorf("SemVer.String() = %q, want %q", got.String(), tt.Version)
+		}
+	}
+	invalid := []string{"", "1", "1.2", "1.2.3.4", "v1.2.3", "01.2.3", "1.02.3", "1.2.03", "1.2.3-", "1.2.3-01", "1.2.3-alpha..1", "1.2.3+", "1.2.3+a..b", "1.2.3-al_pha", "-1.2.3", "1.2.3 ", "18446744073709551616.0.0", "1.2.3-ä"}
+	for _, version := range invalid {
+		if _, err := ParseSemVer(version); err == nil {
+			t.Errorf("ParseSemVer(%q) did not return an error", version)
+		} else if e, ok := err.(*SemVerError); !ok || e.Version != version {
+			t.Errorf("ParseSemVer(%q) returned unexpected error %v", version, err)
+		}
+	}
+}
+
+func TestCompareSemVer(t *testing.T) {
+	for i, a := range semVerPrecedence {
+		va, _ := ParseSemVer(a)
+		for j, b := range semVerPrecedence {
+			want := cmp.Compare(i, j)
+			got, err := CompareSemVer(a, b)
+			if err != nil || got != want {
+				t.Errorf("CompareSemVer(%q, %q) = %d, %v, want %d", a, b, got, err, want)
+			}
+			vb, _ := ParseSemVer(b)
+			if got := bytes.Compare(va.AppendKey(nil), vb.AppendKey(nil)); got != want {
+				t.Errorf("comparing keys of %q and %q returned %d, want %d", a, b, got, want)
+			}
+		}
+	}
+	if got, err := CompareSemVer("1.0.0+a", "1.0.0+b"); err != nil || got != 0 {
+		t.Errorf("CompareSemVer does not ignore build metadata: %d, %v", got, err)
+	}
+	if _, err := CompareSemVer("1.0.0", "1.0"); err == nil {
+		t.Error("CompareSemVer did not return an error for an invalid version")
+	}
+}
+
+func TestSortSemVer(t *testing.T) {
+	for _, n := range []int{1, 10} {
+		var versions []string
+		for range n {
+			versions = append(versions, semVerPrecedence...)
+		}
+		want := slices.Clone(versions)
+		slices.SortStableFunc(want, func(a, b string) int {
+			return cmp.Compare(slices.Index(semVerPrecedence, a), slices.Index(semVerPrecedence, b))
+		})
+		random.Shuffle(len(versions), func(i, j int) { versions[i], versions[j] = versions[j], versions[i] })
+		if err := SortSemVer(versions); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(versions, want) {
+			t.Errorf("SortSemVer result [%q] does not match expected value [%q]", versions, want)
+		}
+	}
+
+	versions := []string{"1.0.0+b", "1.0.0-rc.1", "1.0.0+a", "0.1.0"}
+	if err := SortSemVer(versions); err != nil || !reflect.DeepEqual(versions, []string{"0.1.0", "1.0.0-rc.1", "1.0.0+b", "1.0.0+a"}) {
+		t.Errorf("SortSemVer result [%q], %v is not stable", versions, err)
+	}
+
+	invalid := []string{"1.0.0", "v1.0.0", "0.1.0", "1.0"}
+	err := SortSemVer(invalid)
+	if err == nil || !strings.Contains(err.Error(), `"v1.0.0"`) || !strings.Contains(err.Error(), `"1.0"`) {
+		t.Errorf("SortSemVer returned %v, expected both invalid versions to be reported", err)
+	}
+	if !reflect.DeepEqual(invalid, []string{"1.0.0", "v1.0.0", "0.1.0", "1.0"}) {
+		t.Errorf("SortSemVer modified the input to [%q] despite invalid versions", invalid)
+	}
+}
+
+func TestFoldCompare(t *testing.T) {
+	tests := []struct {
+		A    string
+		B    string
+		Want int
+	}{
+		{"apple", "Zebra", -1},
+		{"Apple", "apple", -1},
+		{"apple", "apple", 0},
+		{"APPLE", "apples", -1},
+		{"_x", "a", -1},
+		{"K", "k", 1},
+		{"Kb", "Ka", 1},
+		{"straße", "STRASSE", 1},
+		{"ẞ", "ß", 1},
+		{"ßa", "ẞb", -1},
+		{"Σίσυφος", "σίσυφος", -1},
+		{"ÄPFEL", "äpfel", -1},
+		{"äpfel", "Zebra", 1},
+		{"\xff", "\xfe", 1},
+	}
+	for _, tt := range tests {
+		if got := FoldCompare(tt.A, tt.B); got != tt.Want {
+			t.Errorf("FoldCompare(%q, %q) = %d, want %d", tt.A, tt.B, got, tt.Want)
+		}
+		if got := FoldCompare(tt.B, tt.A); got != -tt.Want {
+			t.Errorf("FoldCompare(%q, %q) = %d, want %d", tt.B, tt.A, got, -tt.Want)
+		}
+	}
+}
+
+func TestFoldSort(t *testing.T) {
+	options := []struct {
+		Name    string
+		Options FoldOptions
+	}{
+		{"default", FoldOptions{}},
+		{"articles", FoldOptions{Articles: EnglishArticles}},
+		{"punctuation", FoldOptions{IgnorePunctuation: true}},
+		{"both", FoldOptions{Articles: EnglishArticles, IgnorePunctuation: true}},
+	}
+	words := []string{"a", "A", "b", "B", "k", "K", "K", "ß", "ẞ", "ss", "σ", "Σ", "ς", "é", "É", "the", "The", " ", "\"", "'", "\x00", "\xff", "an", "x"}
+	for _, opt := range options {
+		for _, n := range []int{10, 1000} {
+			values := make([]string, n)
+			for i := range values {
+				for range random.IntN(4) {
+					values[i] += words[random.IntN(len(words))]
+				}
+			}
+			want := slices.Clone(values)
+			slices.SortFunc(want, opt.Options.Compare)
+			opt.Options.Sort(values)
+			if !reflect.DeepEqual(values, want) {
+				t.Errorf("FoldOptions.Sort with %s options does not produce the same output as slices.SortFunc for %d items.", opt.Name, n)
+			}
+		}
+	}
+
+	values := []string{"zebra", "The Beatles", "\"Heroes\"", "a-ha", "An Orchestra", "ABBA", "Theater", "the the"}
+	FoldOptions{Articles: EnglishArticles, IgnorePunctuation: true}.Sort(values)
+	want := []string{"a-ha", "ABBA", "The Beatles", "\"Heroes\"", "An Orchestra", "the the", "Theater", "zebra"}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("FoldOptions.Sort result [%q] does not match expected value [%q]", values, want)
+	}
+	if got := FoldSort([]string{"b", "B", "a", "A"}); !reflect.DeepEqual(got, []string{"A", "a", "B", "b"}) {
+		t.Errorf("FoldSort result [%q] does not match expected value", got)
+	}
+}
+
+func TestCollateSort(t *testing.T) {
+	latin := strings.Split("abcdefghijklmnopqrstuvwxyz", "")
+	tests := []struct {
+		Name      string
+		Tailoring Tailoring
+		Input     []string
+		Want      []string
+	}{
+		{
+			"no rules",
+			Tailoring{},
+			[]string{"b", "A", "a", "B", "1", "ä"},
+			[]string{"1", "A", "a", "B", "b", "ä"},
+		},
+		{
+			"German",
+			Tailoring{Expansions: map[string]string{"ß": "ss", "ä": "a", "ö": "o", "ü": "u"}},
+			[]string{"Strasse", "Straße", "Strase", "Strasze", "Äpfel", "Apfel", "Zucker", "Übel", "Ubel"},
+			[]string{"Apfel", "Äpfel", "Strase", "Strasse", "Straße", "Strasze", "Ubel", "Übel", "Zucker"},
+		},
+		{
+			"Swedish",
+			Tailoring{Alphabet: append(slices.Clone(latin), "å", "ä", "ö")},
+			[]string{"öl", "Åsa", "zebra", "ärlig", "Anna", "123", "ängel", "Ödla", "émile"},
+			[]string{"123", "Anna", "zebra", "Åsa", "ängel", "ärlig", "Ödla", "öl", "émile"},
+		},
+		{
+			"Czech",
+			Tailoring{Alphabet: []string{"a", "b", "c", "d", "e", "f", "g", "h", "ch", "i", "j"}},
+			[]string{"chata", "hrad", "cibule", "Chleba", "ideal"},
+			[]string{"cibule", "hrad", "chata", "Chleba", "ideal"},
+		},
+	}
+	for _, tt := range tests {
+		c := NewTailoredCollator(tt.Tailoring)
+		got := CollateSort(slices.Clone(tt.Input), c)
+		if !reflect.DeepEqual(got, tt.Want) {
+			t.Errorf("CollateSort with %s rules result [%q] does not match expected value [%q]", tt.Name, got, tt.Want)
+		}
+		for i := 1; i < len(tt.Want); i++ {
+			if CollateCompare(c, tt.Want[i-1], tt.Want[i]) >= 0 {
+				t.Errorf("CollateCompare with %s rules does not order %q before %q", tt.Name, tt.Want[i-1], tt.Want[i])
+			}
+		}
+	}
+
+	// Collators returning identical keys keep the original order
+	byLength := CollatorFunc(func(dst []byte, s string) []byte { return append(dst, byte(len(s))) })
+	got := CollateSort([]string{"bb", "a", "cc", "b", "aa"}, byLength)
+	if want := []string{"a", "b", "bb", "cc", "aa"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CollateSort with CollatorFunc result [%q] does not match expected value [%q]", got, want)
+	}
+}
+
+func randomFloats[T float32 | float64](n int) []T {
+	special := []T{T(math.NaN()), -T(math.NaN()), T(math.Inf(1)), T(math.Inf(-1)), 0, T(math.Copysign(0, -1)), 1, -1}
+	values := make([]T, n)
+	for i := range values {
+		if random.IntN(2) == 0 {
+			values[i] = special[random.IntN(len(special))]
+		} else {
+			values[i] = T(random.NormFloat64())
+		}
+	}
+	// NaNs with different payloads
+	values[0] = T(math.Float64frombits(0x7FF0000000000F01))
+	values[1] = T(math.Float64frombits(0xFFF0000000000F02))
+	return values
+}
+
+func floatBits[T float32 | float64](values []T) []uint64 {
+	bits := make([]uint64, len(values))
+	for i, v := range values {
+		bits[i] = math.Float64bits(float64(v))
+	}
+	return bits
+}
+
+func testFloatOrder[T float32 | float64](t *testing.T) {
+	algorithms := []struct {
+		Name string
+		Func func([]T, func(a, b T) int) []T
+	}{
+		{"InsertionSortFunc", InsertionSortFunc[T]},
+		{"MergeSortFunc", MergeSortFunc[T]},
+		{"SortStableFunc", SortStableFunc[T]},
+	}
+	for _, order := range []FloatOrder{NaNFirst, NaNLast, TotalOrder} {
+		compare := CompareFloats[T](order)
+		values := randomFloats[T](1000)
+		want := slices.Clone(values)
+		slices.SortStableFunc(want, compare)
+		got := RadixSortFloats(slices.Clone(values), order)
+		if !reflect.DeepEqual(floatBits(got), floatBits(want)) {
+			t.Errorf("RadixSortFloats in order %d does not produce the same output as slices.SortStableFunc.", order)
+		}
+		// Stable algorithms have to produce exactly the same bits
+		for _, alg := range algorithms {
+			if got := alg.Func(slices.Clone(values), compare); !reflect.DeepEqual(floatBits(got), floatBits(want)) {
+				t.Errorf("%s in order %d does not produce the same output as slices.SortStableFunc.", alg.Name, order)
+			}
+		}
+		if got := SortFunc(slices.Clone(values), compare); !slices.IsSortedFunc(got, compare) {
+			t.Errorf("SortFunc in order %d does not sort the values.", order)
+		}
+		half := len(want) / 2
+		a := slices.Clone(want[:half])
+		b := slices.Clone(want[half:])
+		if merged := MergeSortedSetsFunc(b, a, compare); !slices.IsSortedFunc(merged, compare) || len(merged) != len(want) {
+			t.Errorf("MergeSortedSetsFunc in order %d does not merge the values.", order)
+		}
+	}
+
+	values := []T{T(math.NaN()), 1, T(math.Copysign(0, -1)), T(math.Inf(-1)), -T(math.NaN()), 0}
+	wants := map[FloatOrder][]uint64{
+		NaNFirst:   floatBits([]T{T(math.NaN()), -T(math.NaN()), T(math.Inf(-1)), T(math.Copysign(0, -1)), 0, 1}),
+		NaNLast:    floatBits([]T{T(math.Inf(-1)), T(math.Copysign(0, -1)), 0, 1, T(math.NaN()), -T(math.NaN())}),
+		TotalOrder: floatBits([]T{-T(math.NaN()), T(math.Inf(-1)), T(math.Copysign(0, -1)), 0, 1, T(math.NaN())}),
+	}
+	for order, want := range wants {
+		if got := floatBits(RadixSortFloats(slices.Clone(values), order)); !reflect.DeepEqual(got, want) {
+			t.Errorf("RadixSortFloats in order %d result %x does not match expected value %x", order, got, want)
+		}
+	}
+}
+
+func TestFloatOrder(t *testing.T) {
+	t.Run("float32", testFloatOrder[float32])
+	t.Run("float64", testFloatOrder[float64])
+}
+
+// randomNaNs returns values of which most are NaNs with random signs and payloads, with the rest taken from randomFloats.
+func randomNaNs[T float32 | float64](n int) []T {
+	values := randomFloats[T](n)
+	for i := range values {
+		if random.IntN(4) != 0 {
+			values[i] = T(math.Float64frombits(0x7FF8000000000000 | random.Uint64()&0x80000000000FFFFF))
+		}
+	}
+	return values
+}
+
+func testFloatsAlgorithms[T float32 | float64](t *testing.T) {
+	algorithms := []struct {
+		Name   string
+		Func   func([]T, FloatOrder) []T
+		Stable bool
+		Desc   bool
+	}{
+		{"InsertionSortFloats", InsertionSortFloats[T], true, false},
+		{"QuickSortFloats", QuickSortFloats[T], false, false},
+		{"MergeSortFloats", MergeSortFloats[T], true, false},
+		{"RadixSortFloats", RadixSortFloats[T], true, false},
+		{"SortFloats", SortFloats[T], false, false},
+		{"SortStableFloats", SortStableFloats[T], true, false},
+		{"MergeSortFloatsDesc", MergeSortFloatsDesc[T], true, true},
+		{"RadixSortFloatsDesc", RadixSortFloatsDesc[T], true, true},
+		{"SortFloatsDesc", SortFloatsDesc[T], false, true},
+		{"SortStableFloatsDesc", SortStableFloatsDesc[T], true, true},
+		{"MergeSortFloatsCtx", floatsCtx(t, MergeSortFloatsCtx[T]), true, false},
+		{"RadixSortFloatsCtx", floatsCtx(t, RadixSortFloatsCtx[T]), true, false},
+		{"InsertSortedFloats", func(items []T, order FloatOrder) []T {
+			var sorted []T
+			for _, v := range items {
+				sorted = InsertSortedFloats(sorted, v, order)
+			}
+			return sorted
+		}, true, false},
+	}
+	for _, order := range []FloatOrder{NaNFirst, NaNLast, TotalOrder} {
+		compare := CompareFloats[T](order)
+		reverse := func(a, b T) int { return compare(b, a) }
+		for _, n := range []int{0, 1, 10, 100, 2000} {
+			values := randomNaNs[T](max(n, 2))[:n]
+			// Sorting in total order makes results comparable independently of the order of equal values
+			canonical := floatBits(RadixSortFloats(slices.Clone(values), TotalOrder))
+			for _, alg := range algorithms {
+				cmp := compare
+				if alg.Desc {
+					cmp = reverse
+				}
+				want := slices.Clone(values)
+				slices.SortStableFunc(want, cmp)
+				got := alg.Func(slices.Clone(values), order)
+				if alg.Stable && !reflect.DeepEqual(floatBits(got), floatBits(want)) {
+					t.Errorf("%s in order %d for %d values does not produce the same output as slices.SortStableFunc.", alg.Name, order, n)
+				}
+				if !slices.IsSortedFunc(got, cmp) || !reflect.DeepEqual(floatBits(RadixSortFloats(got, TotalOrder)), canonical) {
+					t.Errorf("%s in order %d does not sort %d values.", alg.Name, order, n)
+				}
+			}
+
+			want := slices.SortedStableFunc(slices.Values(values), compare)
+			a := slices.SortedStableFunc(slices.Values(values[:n/3]), compare)
+			b := slices.SortedStableFunc(slices.Values(values[n/3:]), compare)
+			if got := MergeSortedSetsFloats(a, b, order); !reflect.DeepEqual(floatBits(got), floatBits(want)) {
+				t.Errorf("MergeSortedSetsFloats in order %d for %d values does not produce the same output as slices.SortStableFunc.", order, n)
+			}
+		}
+	}
+}
+
+// floatsCtx adapts a cancellable sorting function for floating point values to the signature of the other ones, failing the test on errors.
+func floatsCtx[T float32 | float64](t *testing.T, fn func(context.Context, []T, FloatOrder) error) func([]T, FloatOrder) []T {
+	return func(items []T, order FloatOrder) []T {
+		if err := fn(context.Background(), items, order); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		return items
+	}
+}
+
+func TestFloatsAlgorithms(t *testing.T) {
+	t.Run("float32", testFloatsAlgorithms[float32])
+	t.Run("float64", testFloatsAlgorithms[float64])
+}
+
+func TestSortFloatsCtxCancelled(t *testing.T) {
+	algorithms := []struct {
+		Name string
+		Func func(context.Context, []float64, FloatOrder) error
+	}{
+		{"MergeSortFloatsCtx", MergeSortFloatsCtx[float64]},
+		{"RadixSortFloatsCtx", RadixSortFloatsCtx[float64]},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, alg := range algorithms {
+		values := randomNaNs[float64](10000)
+		want := floatBits(RadixSortFloats(slices.Clone(values), TotalOrder))
+		if err := alg.Func(ctx, values, NaNLast); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s with cancelled context returned %v, want %v", alg.Name, err, context.Canceled)
+		}
+		if got := floatBits(RadixSortFloats(values, TotalOrder)); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s with cancelled context lost or changed elements", alg.Name)
+		}
+	}
+}
+
+func randomArrays[T ByteArray](n int, prefix int) []T {
+	items := make([]T, n)
+	for i := range items {
+		b := arrayBytes(&items[i])
+		source.Read(b[prefix:])
+	}
+	return items
+}
+
+func testArrays[T ByteArray](t *testing.T) {
+	tests := []struct {
+		Name  string
+		Input []T
+	}{
+		{"empty", []T{}},
+		{"single", randomArrays[T](1, 0)},
+		{"small", randomArrays[T](20, 0)},
+		{"1000 random", randomArrays[T](1000, 0)},
+		{"100000 random", randomArrays[T](100000, 0)},
+		{"common prefix", randomArrays[T](1000, 3)},
+		{"duplicates", slices.Repeat(randomArrays[T](300, 0), 4)},
+		{"identical", make([]T, 1000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			want := slices.Clone(tt.Input)
+			slices.SortFunc(want, CompareArrays)
+			if got := RadixSortArrays(slices.Clone(tt.Input)); !slices.Equal(got, want) {
+				t.Errorf("RadixSortArrays result for %s does not match slices.SortFunc", tt.Name)
+			}
+			if got := UniqueArrays(slices.Clone(tt.Input)); !slices.Equal(got, slices.Compact(slices.Clone(want))) {
+				t.Errorf("UniqueArrays result for %s does not match slices.Compact", tt.Name)
+			}
+
+			type item struct {
+				Key   T
+				Index int
+			}
+			items := make([]item, len(tt.Input))
+			for i, k := range tt.Input {
+				items[i] = item{k, i}
+			}
+			wantItems := slices.Clone(items)
+			slices.SortStableFunc(wantItems, func(a, b item) int { return CompareArrays(a.Key, b.Key) })
+			key := func(v item) T { return v.Key }
+			if got := RadixSortByArray(items, key); !slices.Equal(got, wantItems) {
+				t.Errorf("RadixSortByArray result for %s is not sorted stably", tt.Name)
+			}
+
+			for i, k := range want {
+				if pos, ok := SearchArrays(want, k); !ok || want[pos] != k || (pos > 0 && want[pos-1] == k) || pos > i {
+					t.Fatalf("SearchArrays returned %d, %t for item at position %d", pos, ok, i)
+				}
+				if pos, ok := SearchByArray(items, k, key); !ok || items[pos].Key != k {
+					t.Fatalf("SearchByArray returned %d, %t for item at position %d", pos, ok, i)
+				}
+			}
+		})
+	}
+	t.Run("missing", func(t *testing.T) {
+		items := RadixSortArrays(randomArrays[T](100, 0))
+		var lowest, highest T
+		for i := range arrayBytes(&highest) {
+			highest[i] = 0xFF
+		}
+		if pos, ok := SearchArrays(items, lowest); ok || pos != 0 {
+			t.Errorf("SearchArrays returned %d, %t for the lowest value, expected 0, false", pos, ok)
+		}
+		if pos, ok := SearchArrays(items, highest); ok || pos != len(items) {
+			t.Errorf("SearchArrays returned %d, %t for the highest value, expected %d, false", pos, ok, len(items))
+		}
+	})
+}
+
+func TestRadixSortArrays(t *testing.T) {
+	t.Run("[4]byte", testArrays[[4]byte])
+	t.Run("[16]byte", testArrays[[16]byte])
+	t.Run("[20]byte", testArrays[[20]byte])
+	t.Run("[32]byte", testArrays[[32]byte])
+	t.Run("[64]byte", testArrays[[64]byte])
+}
+
+func TestSort128(t *testing.T) {
+	bigUint := func(v Uint128) *big.Int {
+		return new(big.Int).Add(new(big.Int).Lsh(new(big.Int).SetUint64(v.Hi), 64), new(big.Int).SetUint64(v.Lo))
+	}
+	bigInt := func(v Int128) *big.Int {
+		return new(big.Int).Add(new(big.Int).Lsh(big.NewInt(v.Hi), 64), new(big.Int).SetUint64(v.Lo))
+	}
+	// Few distinct high halves make sure the low halves have to be compared
+	unsigned := make([]Uint128, 10000)
+	signed := make([]Int128, len(unsigned))
+	for i := range unsigned {
+		unsigned[i] = Uint128{Hi: random.Uint64N(4) << 62, Lo: random.Uint64()}
+		signed[i] = Int128{Hi: int64(random.Uint64N(4)<<62) >> 1, Lo: random.Uint64()}
+	}
+	signed = append(signed, Int128From64(-1), Int128From64(math.MinInt64), Int128From64(0), Int128From64(math.MaxInt64))
+	unsigned = append(unsigned, Uint128From64(0), Uint128From64(math.MaxUint64), Uint128{math.MaxUint64, math.MaxUint64})
+
+	t.Run("Compare", func(t *testing.T) {
+		for i := 1; i < len(unsigned); i++ {
+			if got, want := unsigned[i-1].Compare(unsigned[i]), bigUint(unsigned[i-1]).Cmp(bigUint(unsigned[i])); got != want {
+				t.Fatalf("Uint128.Compare(%v, %v) = %d, expected %d", unsigned[i-1], unsigned[i], got, want)
+			}
+			if got, want := Compare128(signed[i-1], signed[i]), bigInt(signed[i-1]).Cmp(bigInt(signed[i])); got != want {
+				t.Fatalf("Compare128(%v, %v) = %d, expected %d", signed[i-1], signed[i], got, want)
+			}
+		}
+		if Int128From64(-5).Compare(Int128From64(3)) != -1 {
+			t.Error("Int128From64 does not extend the sign")
+		}
+	})
+
+	wantUnsigned := slices.SortedFunc(slices.Values(unsigned), func(a, b Uint128) int { return bigUint(a).Cmp(bigUint(b)) })
+	wantSigned := slices.SortedFunc(slices.Values(signed), func(a, b Int128) int { return bigInt(a).Cmp(bigInt(b)) })
+	t.Run("RadixSort128", func(t *testing.T) {
+		if got := RadixSort128(slices.Clone(unsigned)); !slices.Equal(got, wantUnsigned) {
+			t.Error("RadixSort128 result for Uint128 is not sorted")
+		}
+		if got := RadixSort128(slices.Clone(signed)); !slices.Equal(got, wantSigned) {
+			t.Error("RadixSort128 result for Int128 is not sorted")
+		}
+	})
+	t.Run("MergeSort128", func(t *testing.T) {
+		if got := MergeSort128(slices.Clone(unsigned)); !slices.Equal(got, wantUnsigned) {
+			t.Error("MergeSort128 result for Uint128 is not sorted")
+		}
+		if got := MergeSort128(slices.Clone(signed)); !slices.Equal(got, wantSigned) {
+			t.Error("MergeSort128 result for Int128 is not sorted")
+		}
+	})
+	t.Run("RadixSortBy128", func(t *testing.T) {
+		type item struct {
+			ID    Int128
+			Index int
+		}
+		items := make([]item, len(signed))
+		for i := range items {
+			// Duplicate IDs make the stability observable
+			items[i] = item{signed[i/2], i}
+		}
+		want := slices.Clone(items)
+		slices.SortStableFunc(want, func(a, b item) int { return a.ID.Compare(b.ID) })
+		if got := RadixSortBy128(items, func(v item) Int128 { return v.ID }); !slices.Equal(got, want) {
+			t.Error("RadixSortBy128 result is not sorted stably")
+		}
+	})
+}
+
+func TestSortTimes(t *testing.T) {
+	now := time.Now()
+	randomTimes := func(n int, spread time.Duration) []time.Time {
+		times := make([]time.Time, n)
+		for i := range times {
+			// Stripping the monotonic clock reading forces the wall clock to be used
+			times[i] = now.Add(time.Duration(random.Int64N(int64(spread)) - int64(spread/2))).Round(0)
+		}
+		return times
+	}
+	monotonic := make([]time.Time, 1000)
+	for i := range monotonic {
+		monotonic[i] = now.Add(time.Duration(random.Int64N(int64(time.Hour))))
+	}
+	distant := append(randomTimes(1000, 100*365*24*time.Hour),
+		time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(9999, 12, 31, 23, 59, 59, 999999999, time.UTC),
+		time.Date(1500, 6, 1, 0, 0, 0, 1, time.UTC),
+		time.Date(1500, 6, 1, 0, 0, 0, 0, time.UTC),
+	)
+	tests := []struct {
+		Name  string
+		Input []time.Time
+	}{
+		{"empty", []time.Time{}},
+		{"single", randomTimes(1, time.Hour)},
+		{"wall clock", randomTimes(1000, 24*time.Hour)},
+		{"monotonic clock", monotonic},
+		{"mixed clocks", append(slices.Clone(monotonic), randomTimes(1000, time.Hour)...)},
+		{"distant times", distant},
+		{"time zones", append(randomTimes(100, time.Hour), now.In(time.FixedZone("A", 3600)), now.In(time.FixedZone("B", -7200)))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			want := slices.Clone(tt.Input)
+			slices.SortStableFunc(want, time.Time.Compare)
+			if got := SortTimes(slices.Clone(tt.Input)); !slices.EqualFunc(got, want, time.Time.Equal) {
+				t.Errorf("SortTimes result for %s is not sorted chronologically", tt.Name)
+			}
+
+			type event struct {
+				At    time.Time
+				Index int
+			}
+			events := make([]event, len(tt.Input))
+			for i := range events {
+				// Duplicate times make the stability observable
+				events[i] = event{tt.Input[i/2*2], i}
+			}
+			wantEvents := slices.Clone(events)
+			slices.SortStableFunc(wantEvents, func(a, b event) int { return a.At.Compare(b.At) })
+			if got := SortByTime(events, func(e event) time.Time { return e.At }); !slices.Equal(got, wantEvents) {
+				t.Errorf("SortByTime result for %s is not sorted stably", tt.Name)
+			}
+		})
+	}
+}
+
+func TestSortDurations(t *testing.T) {
+	durations := make([]time.Duration, 1000)
+	fillRandom(durations)
+	durations = append(durations, math.MinInt64, math.MaxInt64, 0, -1)
+	want := slices.Sorted(slices.Values(durations))
+	if got := SortDurations(durations); !slices.Equal(got, want) {
+		t.Error("SortDurations result is not sorted")
+	}
+}
+
+func randomAddr() netip.Addr {
+	switch random.IntN(4) {
+	case 0:
+		return netip.AddrFrom4([4]byte{10, 0, byte(random.IntN(2)), byte(random.IntN(256))})
+	case 1:
+		var ip [16]byte
+		source.Read(ip[:])
+		return netip.AddrFrom16(ip)
+	case 2:
+		// Equal addresses with different zones have to be ordered by their zone
+		return netip.AddrFrom16([16]byte{0xfe, 0x80, 15: byte(random.IntN(4))}).WithZone([]string{"", "eth0", "eth1"}[random.IntN(3)])
+	default:
+		return netip.AddrFrom16(netip.AddrFrom4([4]byte{10, 0, 0, byte(random.IntN(256))}).As16())
+	}
+}
+
+func TestSortAddrs(t *testing.T) {
+	addrs := []netip.Addr{{}, {}}
+	for range 2000 {
+		addrs = append(addrs, randomAddr())
+	}
+	want := slices.SortedFunc(slices.Values(addrs), netip.Addr.Compare)
+	if got := SortAddrs(slices.Clone(addrs)); !slices.Equal(got, want) {
+		t.Errorf("SortAddrs result %v does not match expected value %v", got, want)
+	}
+}
+
+func TestSortPrefixes(t *testing.T) {
+	prefixes := []netip.Prefix{{}, {}}
+	for range 2000 {
+		addr := randomAddr().WithZone("")
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()-random.IntN(9)))
+	}
+	want := slices.SortedFunc(slices.Values(prefixes), ComparePrefixes)
+	if got := SortPrefixes(slices.Clone(prefixes)); !slices.Equal(got, want) {
+		t.Errorf("SortPrefixes result %v does not match expected value %v", got, want)
+	}
+}
+
+func TestAggregatePrefixes(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Input []string
+		Want  []string
+	}{
+		{"empty", nil, nil},
+		{"siblings", []string{"10.0.0.128/25", "10.0.0.0/25"}, []string{"10.0.0.0/24"}},
+		{"contained", []string{"10.0.0.0/26", "10.0.0.0/24", "10.0.0.200/32"}, []string{"10.0.0.0/24"}},
+		{"unmasked", []string{"10.0.0.1/24", "10.0.1.1/24"}, []string{"10.0.0.0/23"}},
+		{"cascade", []string{"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/25", "10.0.1.0/24"}, []string{"10.0.0.0/23"}},
+		{"not siblings", []string{"10.0.1.0/24", "10.0.2.0/24"}, []string{"10.0.1.0/24", "10.0.2.0/24"}},
+		{"families", []string{"2001:db8::/33", "10.0.0.0/8", "2001:db8:8000::/33", "::ffff:10.0.0.0/104"}, []string{"10.0.0.0/8", "::ffff:10.0.0.0/104", "2001:db8::/32"}},
+		{"everything", []string{"0.0.0.0/1", "128.0.0.0/1", "1.2.3.4/32"}, []string{"0.0.0.0/0"}},
+		{"duplicates", []string{"10.0.0.0/24", "10.0.0.0/24"}, []string{"10.0.0.0/24"}},
+	}
+	parse := func(s []string) []netip.Prefix {
+		var prefixes []netip.Prefix
+		for _, p := range s {
+			prefixes = append(prefixes, netip.MustParsePrefix(p))
+		}
+		return prefixes
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if got := AggregatePrefixes(parse(tt.Input)); !slices.Equal(got, parse(tt.Want)) {
+				t.Errorf("AggregatePrefixes result %v does not match expected value %v", got, tt.Want)
+			}
+		})
+	}
+
+	// Aggregating random prefixes within a /24 has to cover the same addresses using as few prefixes as possible
+	covered := func(prefixes []netip.Prefix) (set [256]bool) {
+		for _, p := range prefixes {
+			for i := range set {
+				set[i] = set[i] || p.Contains(netip.AddrFrom4([4]byte{10, 0, 0, byte(i)}))
+			}
+		}
+		return set
+	}
+	for range 100 {
+		var a, b []netip.Prefix
+		for range random.IntN(20) {
+			a = append(a, netip.PrefixFrom(netip.AddrFrom4([4]byte{10, 0, 0, byte(random.IntN(256))}), 24+random.IntN(9)))
+			b = append(b, netip.PrefixFrom(netip.AddrFrom4([4]byte{10, 0, 0, byte(random.IntN(256))}), 24+random.IntN(9)))
+		}
+		want := covered(append(slices.Clone(a), b...))
+		got := MergePrefixes(AggregatePrefixes(a), AggregatePrefixes(b))
+		if covered(got) != want {
+			t.Fatalf("MergePrefixes result %v does not cover the same addresses as the input", got)
+		}
+		if !slices.IsSortedFunc(got, ComparePrefixes) {
+			t.Fatalf("MergePrefixes result %v is not sorted", got)
+		}
+		for i := 1; i < len(got); i++ {
+			if got[i-1].Overlaps(got[i]) {
+				t.Fatalf("MergePrefixes result %v contains overlapping prefixes", got)
+			}
+			if _, ok := joinPrefixes(got[i-1], got[i]); ok {
+				t.Fatalf("MergePrefixes result %v contains prefixes that can be joined", got)
+			}
+		}
+	}
+}
+
+type testOwner struct {
+	Name string
+}
+
+type testRecord struct {
+	Name      string `sort:"2"`
+	Priority  int8   `sort:"1,desc"`
+	Score     float64
+	Active    bool
+	CreatedAt time.Time
+	Owner     testOwner
+	Tags      []string
+	hidden    int
+	Index     int
+}
+
+func TestSortStructs(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := make([]testRecord, 500)
+	for i := range records {
+		records[i] = testRecord{
+			Name:      string(rune('a' + random.IntN(4))),
+			Priority:  int8(random.IntN(5) - 2),
+			Score:     float64(random.IntN(3)) / 2,
+			Active:    random.IntN(2) == 0,
+			CreatedAt: base.Add(time.Duration(random.IntN(10)) * time.Hour),
+			Owner:     testOwner{string(rune('x' + random.IntN(3)))},
+			Index:     i,
+		}
+	}
+	tests := []struct {
+		Spec string
+		Want func(a, b testRecord) int
+	}{
+		{"Name", func(a, b testRecord) int { return cmp.Compare(a.Name, b.Name) }},
+		{"Name,-CreatedAt", func(a, b testRecord) int {
+			return cmp.Or(cmp.Compare(a.Name, b.Name), b.CreatedAt.Compare(a.CreatedAt))
+		}},
+		{" -Active , +Score ", func(a, b testRecord) int {
+			return cmp.Or(-cmp.Compare(b2i(a.Active), b2i(b.Active)), cmp.Compare(a.Score, b.Score))
+		}},
+		{"Owner.Name,-Priority", func(a, b testRecord) int {
+			return cmp.Or(cmp.Compare(a.Owner.Name, b.Owner.Name), cmp.Compare(b.Priority, a.Priority))
+		}},
+		{"", func(a, b testRecord) int {
+			return cmp.Or(cmp.Compare(b.Priority, a.Priority), cmp.Compare(a.Name, b.Name))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Spec, func(t *testing.T) {
+			want := slices.Clone(records)
+			slices.SortStableFunc(want, tt.Want)
+			got := slices.Clone(records)
+			if err := SortStructs(got, tt.Spec); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("SortStructs result for %q is not sorted stably", tt.Spec)
+			}
+		})
+	}
+
+	t.Run("pointers", func(t *testing.T) {
+		pointers := []*testRecord{&records[0], nil, &records[1], &records[2], nil}
+		want := []*testRecord{&records[0], &records[1], &records[2]}
+		slices.SortStableFunc(want, func(a, b *testRecord) int { return cmp.Compare(a.Name, b.Name) })
+		want = append([]*testRecord{nil, nil}, want...)
+		if err := SortStructs(&pointers, "Name"); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(pointers, want) {
+			t.Errorf("SortStructs result %v does not match expected value %v", pointers, want)
+		}
+	})
+
+	t.Run("embedded nil pointer", func(t *testing.T) {
+		type Inner struct {
+			Rank int
+		}
+		type outer struct {
+			*Inner
+			Index int
+		}
+		items := []outer{{&Inner{2}, 0}, {nil, 1}, {&Inner{-1}, 2}, {&Inner{0}, 3}}
+		if err := SortStructs(items, "Rank"); err != nil {
+			t.Fatal(err)
+		}
+		// The nil pointer is compared like a rank of 0
+		want := []int{2, 1, 3, 0}
+		for i, item := range items {
+			if item.Index != want[i] {
+				t.Fatalf("SortStructs result %v does not match expected order %v", items, want)
+			}
+		}
+	})
+
+	t.Run("nested pointer", func(t *testing.T) {
+		type item struct {
+			Owner *testOwner
+			Index int
+		}
+		items := []item{{&testOwner{"y"}, 0}, {nil, 1}, {&testOwner{"x"}, 2}, {&testOwner{""}, 3}}
+		if err := SortStructs(items, "Owner.Name"); err != nil {
+			t.Fatal(err)
+		}
+		// The nil pointer is compared like an empty name and stays before the owner with an empty name
+		want := []int{1, 3, 2, 0}
+		for i, item := range items {
+			if item.Index != want[i] {
+				t.Fatalf("SortStructs result %v does not match expected order %v", items, want)
+			}
+		}
+	})
+
+	errorTests := []struct {
+		Name  string
+		Items any
+		Spec  string
+	}{
+		{"nil", nil, "Name"},
+		{"not a slice", testRecord{}, "Name"},
+		{"not structs", []int{1, 2}, "Name"},
+		{"unknown field", records, "Name,Unknown"},
+		{"unexported field", records, "hidden"},
+		{"unsortable field", records, "Tags"},
+		{"not a struct", records, "Name.Length"},
+		{"empty field", records, "Name,,Score"},
+		{"no tags", []testOwner{}, ""},
+		{"duplicate priority", []struct {
+			A int `sort:"1"`
+			B int `sort:"1"`
+		}{}, ""},
+		{"invalid priority", []struct {
+			A int `sort:"first"`
+		}{}, ""},
+		{"invalid order", []struct {
+			A int `sort:"1,up"`
+		}{}, ""},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var structErr *StructError
+			if err := SortStructs(tt.Items, tt.Spec); !errors.As(err, &structErr) {
+				t.Errorf("SortStructs returned %v, expected a *StructError", err)
+			}
+		})
+	}
+}
+
+func TestSorted(t *testing.T) {
+	values := make([]int, 1000)
+	fillRandom(values)
+	want := slices.Sorted(slices.Values(values))
+
+	if got := Sorted(slices.Values(values)); !slices.Equal(got, want) {
+		t.Error("Sorted result is not sorted")
+	}
+	if got := SortedStable(slices.Values(values)); !slices.Equal(got, want) {
+		t.Error("SortedStable result is not sorted")
+	}
+	if got := slices.Collect(SortedSeq(slices.Values(values))); !slices.Equal(got, want) {
+		t.Error("SortedSeq result is not sorted")
+	}
+	for v := range SortedSeq(slices.Values(values)) {
+		if v != want[0] {
+			t.Errorf("SortedSeq yielded %d first, expected %d", v, want[0])
+		}
+		break
+	}
+	prefix := []int{3, 2, 1}
+	if got := AppendSorted(slices.Clone(prefix), slices.Values(values)); !slices.Equal(got[:3], prefix) || !slices.Equal(got[3:], want) {
+		t.Error("AppendSorted did not sort only the appended values")
+	}
+	if got := Sorted(slices.Values([]int(nil))); len(got) != 0 {
+		t.Errorf("Sorted result for an empty sequence is %v", got)
+	}
+
+	rows := randomRows(500)
+	compare := func(a, b testRow) int { return cmp.Compare(a.Tenant, b.Tenant) }
+	wantRows := slices.Clone(rows)
+	slices.SortStableFunc(wantRows, compare)
+	if got := SortedStableFunc(slices.Values(rows), compare); !slices.Equal(got, wantRows) {
+		t.Error("SortedStableFunc result is not sorted stably")
+	}
+	if got := SortedFunc(slices.Values(rows), compare); !slices.IsSortedFunc(got, compare) || len(got) != len(rows) {
+		t.Error("SortedFunc result is not sorted")
+	}
+
+	m := make(map[string]int)
+	for i := range 1000 {
+		m[randomString(8)] = i
+	}
+	if got := slices.Collect(SortedKeys(m)); !slices.Equal(got, slices.Sorted(maps.Keys(m))) {
+		t.Error("SortedKeys result is not sorted")
+	}
+}
+
+func TestIsSorted(t *testing.T) {
+	tests := []struct {
+		Input    []float64
+		Sorted   bool
+		Strictly bool
+	}{
+		{nil, true, true},
+		{[]float64{1}, true, true},
+		{[]float64{1, 2, 2, 3}, true, false},
+		{[]float64{1, 2, 3}, true, true},
+		{[]float64{2, 1}, false, false},
+		{[]float64{math.NaN(), -1, 0}, true, true},
+		{[]float64{-1, math.NaN()}, false, false},
+	}
+	for _, tt := range tests {
+		if got := IsSorted(tt.Input); got != tt.Sorted {
+			t.Errorf("IsSorted(%v) = %v", tt.Input, got)
+		}
+		if got := IsSortedFunc(tt.Input, CompareFloats[float64](NaNFirst)); got != tt.Sorted {
+			t.Errorf("IsSortedFunc(%v) = %v", tt.Input, got)
+		}
+		if got := IsStrictlySorted(tt.Input); got != tt.Strictly {
+			t.Errorf("IsStrictlySorted(%v) = %v", tt.Input, got)
+		}
+	}
+}
+
+func TestSortedness(t *testing.T) {
+	// Brute force counting for small slices
+	for n := range 60 {
+		values := make([]int8, n)
+		fillRandom(values)
+		for i := range values {
+			values[i] %= 8
+		}
+		want := Sortedness{SortedPrefix: n}
+		if n > 0 {
+			want.Runs = 1
+		}
+		for i := 1; i < n; i++ {
+			if values[i] < values[i-1] {
+				want.Runs++
+				want.SortedPrefix = min(want.SortedPrefix, i)
+			}
+			for j := range i {
+				if values[i] < values[j] {
+					want.Inversions++
+				}
+			}
+		}
+		if got := MeasureSortedness(values); got != want {
+			t.Errorf("MeasureSortedness(%v) = %+v, want %+v", values, got, want)
+		}
+		if got := EstimateSortedness(values); got != want {
+			t.Errorf("EstimateSortedness of %d items = %+v, want the exact %+v", n, got, want)
+		}
+	}
+
+	n := 1 << 18
+	sorted := make([]int, n)
+	for i := range sorted {
+		sorted[i] = i
+	}
+	if got := EstimateSortedness(sorted); got != (Sortedness{Runs: 1, SortedPrefix: n}) {
+		t.Errorf("EstimateSortedness of sorted items = %+v", got)
+	}
+	reversed := slices.Clone(sorted)
+	slices.Reverse(reversed)
+	if got := EstimateSortedness(reversed); got != (Sortedness{Runs: n, Inversions: n * (n - 1) / 2, SortedPrefix: 1}) {
+		t.Errorf("EstimateSortedness of reversed items = %+v", got)
+	}
+
+	// Estimates for random items are within a few standard deviations of the exact values
+	values := make([]int, n)
+	fillRandom(values)
+	exact, estimate := MeasureSortedness(values), EstimateSortedness(values)
+	within := func(got, want int) bool {
+		return math.Abs(float64(got-want)) <= 0.25*float64(want)
+	}
+	if !within(estimate.Runs, exact.Runs) || !within(estimate.Inversions, exact.Inversions) || estimate.SortedPrefix < exact.SortedPrefix {
+		t.Errorf("EstimateSortedness of random items = %+v, exact %+v", estimate, exact)
+	}
+	if EstimateSortedness(values) != estimate {
+		t.Error("EstimateSortedness is not deterministic")
+	}
+	// Sort estimates the sortedness of large slices, so it must not allocate
+	if allocs := testing.AllocsPerRun(10, func() { EstimateSortedness(values) }); allocs != 0 {
+		t.Errorf("EstimateSortedness allocated %v times", allocs)
+	}
+
+	// The sorted prefix beyond √n items is only checked at evenly spaced positions
+	values = slices.Clone(sorted)
+	values[n/2], values[n/2+1] = values[n/2+1], values[n/2]
+	if got := EstimateSortedness(values).SortedPrefix; got < n/2+1 {
+		t.Errorf("EstimateSortedness sorted prefix %d is shorter than the exact %d", got, n/2+1)
+	}
+}
+
+func TestInsertionSortLimit(t *testing.T) {
+	values := make([]int, 1000)
+	fillRandom(values)
+	for i := range values {
+		values[i] %= 100
+	}
+	want := slices.Clone(values)
+	if insertionSortLimit(values, len(values)) {
+		t.Fatal("insertionSortLimit sorted random items within the limit")
+	}
+	slices.Sort(want)
+	if slices.Sort(values); !slices.Equal(values, want) {
+		t.Error("insertionSortLimit did not keep all items when giving up")
+	}
+	slices.Reverse(values[10:20])
+	if !insertionSortLimit(values, 45) || !slices.Equal(values, want) {
+		t.Error("insertionSortLimit did not sort items within the limit")
+	}
+}
@@ -0,0 +1,387 @@
+// Package csvsort sorts the rows of CSV and TSV files by one or more columns.
+//
+// Files are read and written using encoding/csv, so quoted fields may contain separators, quotes and line breaks.
+// Every column is compared as a string, in natural order, as an integer, as a float or as a time, in ascending or descending order.
+// Integers, floats and times are converted into integer keys only once and sorted using radix sort.
+// The sort is stable, so sorting by one column after another yields the same order as sorting by all of them at once, starting with the last one.
+package csvsort
+
+import (
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fossoreslp/go-sort"
+	"github.com/fossoreslp/go-sort/keyenc"
+)
+
+// Type determines how the fields of a column are compared.
+type Type int
+
+const (
+	// String compares fields byte by byte.
+	String Type = iota
+	// Natural compares fields in natural order as defined by sort.NaturalCompare, so "item2" is ordered before "item10".
+	Natural
+	// Int compares fields as 64-bit signed integers.
+	Int
+	// Float compares fields as 64-bit floating point numbers.
+	Float
+	// Time compares fields as times in the layout of the column.
+	Time
+)
+
+var typeNames = [...]string{String: "string", Natural: "natural", Int: "int", Float: "float", Time: "time"}
+
+// String returns the name of the type as accepted by ParseColumn.
+func (t Type) String() string {
+	if t < 0 || int(t) >= len(typeNames) {
+		return "Type(" + strconv.Itoa(int(t)) + ")"
+	}
+	return typeNames[t]
+}
+
+// Column describes a column by which rows are sorted.
+// Fields of Int, Float and Time columns that are empty or cannot be parsed are ordered after all other fields, regardless of the direction.
+// Rows with fewer fields are treated as if the missing fields were empty.
+type Column struct {
+	// Index is the position of the column in every row, starting at 0.
+	Index int
+	// Name identifies the column by its name in the header instead of its index and requires Options.Header to be set.
+	Name string
+	// Type determines how the fields of the column are compared.
+	Type Type
+	// Layout is the layout of Time columns as used by time.Parse, time.RFC3339 if it is empty.
+	Layout string
+	// Descending sorts in descending order of the column.
+	Descending bool
+}
+
+// ParseColumn parses a column of the form COLUMN[:TYPE][:FLAGS], e.g. "3:int:r" or "created:time".
+// COLUMN is either the position of the column starting at 1 or its name in the header.
+// TYPE is one of string (default), natural, int, float and time, with times being parsed according to RFC 3339.
+// FLAGS may consist of the letter r for descending order.
+func ParseColumn(s string) (Column, error) {
+	invalid := func(reason string) (Column, error) {
+		return Column{}, errors.New("csvsort: invalid column " + strconv.Quote(s) + ": " + reason)
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return invalid("expected COLUMN[:TYPE][:FLAGS]")
+	}
+	var c Column
+	if parts[0] == "" {
+		return invalid("missing column")
+	}
+	if n, err := strconv.Atoi(parts[0]); err == nil {
+		if n <= 0 {
+			return invalid("positions start at 1")
+		}
+		c.Index = n - 1
+	} else {
+		c.Name = parts[0]
+	}
+	if len(parts) > 1 && parts[1] != "" {
+		i := slices.Index(typeNames[:], parts[1])
+		if i < 0 {
+			return invalid("unknown type " + strconv.Quote(parts[1]))
+		}
+		c.Type = Type(i)
+	}
+	if len(parts) > 2 {
+		for _, f := range parts[2] {
+			if f != 'r' {
+				return invalid("unknown flag " + strconv.QuoteRune(f))
+			}
+			c.Descending = true
+		}
+	}
+	return c, nil
+}
+
+// Options configures how Sort reads, sorts and writes rows.
+type Options struct {
+	// Comma is the field separator, which is ',' if it is 0. TSV files are read by setting it to '\t'.
+	Comma rune
+	// LazyQuotes allows quotes within unquoted fields and non-doubled quotes within quoted fields, like csv.Reader.LazyQuotes.
+	LazyQuotes bool
+	// Header keeps the first row of the input at the top of the output instead of sorting it as data.
+	Header bool
+	// Columns are the columns by which rows are sorted, comparing by the first one and only by the following ones if the previous ones are equal.
+	// Without columns, rows are ordered by all of their fields as strings.
+	Columns []Column
+}
+
+// Sort reads the rows of all srcs, sorts them and writes them to dst, using the same separator for reading and writing.
+// If Header is set, the first row of every source is its header, which has to be identical for all sources and is only written once.
+// Fields are quoted only if necessary when writing them, so the output may differ from the input in more than the order of the rows.
+func Sort(dst io.Writer, opts Options, srcs ...io.Reader) error {
+	comma := cmp.Or(opts.Comma, ',')
+	var header []string
+	var rows [][]string
+	for i, src := range srcs {
+		r := csv.NewReader(src)
+		r.Comma = comma
+		r.LazyQuotes = opts.LazyQuotes
+		r.FieldsPerRecord = -1
+		if opts.Header {
+			h, err := r.Read()
+			if err == io.EOF {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if header == nil {
+				header = h
+			} else if !slices.Equal(header, h) {
+				return fmt.Errorf("csvsort: the header of input %d differs from the header of the first input", i+1)
+			}
+		}
+		more, err := r.ReadAll()
+		if err != nil {
+			return err
+		}
+		rows = append(rows, more...)
+	}
+	if opts.Header && header == nil {
+		// Without any input there is nothing to write
+		return nil
+	}
+
+	columns := slices.Clone(opts.Columns)
+	for i, c := range columns {
+		if c.Name == "" {
+			continue
+		}
+		if !opts.Header {
+			return errors.New("csvsort: column " + strconv.Quote(c.Name) + " is identified by its name, which requires a header")
+		}
+		if columns[i].Index = slices.Index(header, c.Name); columns[i].Index < 0 {
+			return errors.New("csvsort: column " + strconv.Quote(c.Name) + " not found in the header")
+		}
+		columns[i].Name = ""
+	}
+	if err := SortRows(rows, columns...); err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(dst)
+	w.Comma = comma
+	if header != nil {
+		w.Write(header)
+	}
+	return w.WriteAll(rows)
+}
+
+// SortRows sorts rows by the columns in-place, which have to be identified by their index.
+// Rows are compared by the first column and only by the following ones if the previous columns are equal.
+// Without columns, rows are ordered by all of their fields as strings.
+// The sort is stable, therefore rows for which all columns are equal retain their original order.
+func SortRows(rows [][]string, columns ...Column) error {
+	if err := validate(columns); err != nil {
+		return err
+	}
+	if len(rows) < 2 {
+		return nil
+	}
+	if len(columns) == 0 {
+		columns = allColumns(rows)
+	}
+
+	// Sort a permutation of the rows, with every pass being stable so that sorting by the most significant column last keeps the order established by the others
+	perm := make([]int, len(rows))
+	for i := range perm {
+		perm[i] = i
+	}
+	for i := len(columns) - 1; i >= 0; i-- {
+		sortColumn(rows, perm, columns[i])
+	}
+
+	sorted := make([][]string, len(rows))
+	for i, p := range perm {
+		sorted[i] = rows[p]
+	}
+	copy(rows, sorted)
+	return nil
+}
+
+// Compare returns a function comparing rows by the columns, which produces the same order as SortRows.
+// The columns have to be identified by their index.
+func Compare(columns []Column) func(a, b []string) int {
+	return func(a, b []string) int {
+		cols := columns
+		if len(cols) == 0 {
+			cols = allColumns([][]string{a, b})
+		}
+		for _, c := range cols {
+			if r := c.compare(field(a, c.Index), field(b, c.Index)); r != 0 {
+				return r
+			}
+		}
+		return 0
+	}
+}
+
+// compare compares two fields of the column.
+func (c Column) compare(a, b string) int {
+	var r int
+	switch c.Type {
+	case String:
+		r = strings.Compare(a, b)
+	case Natural:
+		r = sort.NaturalCompare(a, b)
+	default:
+		ka, okA := c.key(a)
+		kb, okB := c.key(b)
+		if !okA || !okB {
+			// Invalid fields are ordered last in both directions
+			return cmpBool(!okA, !okB)
+		}
+		r = sort.Compare128(ka, kb)
+	}
+	if c.Descending {
+		return -r
+	}
+	return r
+}
+
+// key parses a field of an Int, Float or Time column into an unsigned integer with the same order, reporting whether the field is valid.
+// Integers and floats only use the high 64 bits of the key, while times use them for the seconds and the low 64 bits for the nanoseconds.
+func (c Column) key(s string) (sort.Uint128, bool) {
+	s = strings.TrimSpace(s)
+	switch c.Type {
+	case Int:
+		n, err := strconv.ParseInt(s, 10, 64)
+		// Flipping the sign bit orders negative numbers first when the keys are compared as unsigned integers
+		return sort.Uint128{Hi: uint64(n) ^ 1<<63}, err == nil
+	case Float:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsNaN(f) {
+			return sort.Uint128{}, false
+		}
+		// Negative zero is equal to positive zero, so it has to have the same key
+		if f == 0 {
+			f = 0
+		}
+		return sort.Uint128{Hi: keyenc.Float64Key(f)}, true
+	case Time:
+		t, err := time.Parse(cmp.Or(c.Layout, time.RFC3339), s)
+		return sort.Uint128{Hi: uint64(t.Unix()) ^ 1<<63, Lo: uint64(t.Nanosecond())}, err == nil
+	}
+	panic("unreachable")
+}
+
+// sortColumn stably sorts the permutation of rows by a single column.
+func sortColumn(rows [][]string, perm []int, c Column) {
+	if c.Type == String && !c.Descending {
+		keys := make([]string, len(perm))
+		for i, p := range perm {
+			keys[i] = field(rows[p], c.Index)
+		}
+		sort.SortPairs(keys, perm)
+		return
+	}
+	if c.Type == String || c.Type == Natural {
+		entries := make([]entry[string], len(perm))
+		for i, p := range perm {
+			entries[i] = entry[string]{field(rows[p], c.Index), p}
+		}
+		sort.MergeSortFunc(entries, func(a, b entry[string]) int { return c.compare(a.key, b.key) })
+		for i, e := range entries {
+			perm[i] = e.p
+		}
+		return
+	}
+
+	// Invalid fields are moved to the end first, so only the valid ones have to be sorted
+	valid := make([]entry[sort.Uint128], 0, len(perm))
+	var invalid []int
+	for _, p := range perm {
+		k, ok := c.key(field(rows[p], c.Index))
+		if !ok {
+			invalid = append(invalid, p)
+			continue
+		}
+		// Inverting all bits reverses the order of the keys
+		if c.Descending {
+			k = sort.Uint128{Hi: ^k.Hi, Lo: ^k.Lo}
+		}
+		valid = append(valid, entry[sort.Uint128]{k, p})
+	}
+	if c.Type == Time {
+		sort.RadixSortBy128(valid, func(e entry[sort.Uint128]) sort.Uint128 { return e.key })
+		for i, e := range valid {
+			perm[i] = e.p
+		}
+	} else {
+		// Integers and floats only need a single pass over the high 64 bits
+		keys := make([]uint64, len(valid))
+		for i, e := range valid {
+			keys[i], perm[i] = e.key.Hi, e.p
+		}
+		sort.SortPairs(keys, perm[:len(valid)])
+	}
+	copy(perm[len(valid):], invalid)
+}
+
+// entry is the key of a column for the row at position p.
+type entry[K any] struct {
+	key K
+	p   int
+}
+
+// validate checks that all columns are identified by a valid index and have a known type.
+func validate(columns []Column) error {
+	for _, c := range columns {
+		if c.Name != "" {
+			return errors.New("csvsort: column " + strconv.Quote(c.Name) + " is identified by its name, which requires a header")
+		}
+		if c.Index < 0 {
+			return fmt.Errorf("csvsort: invalid column index %d", c.Index)
+		}
+		if c.Type < String || c.Type > Time {
+			return fmt.Errorf("csvsort: invalid type %v of column %d", c.Type, c.Index)
+		}
+	}
+	return nil
+}
+
+// allColumns returns string columns for all fields of the longest row.
+func allColumns(rows [][]string) []Column {
+	n := 0
+	for _, r := range rows {
+		n = max(n, len(r))
+	}
+	columns := make([]Column, n)
+	for i := range columns {
+		columns[i].Index = i
+	}
+	return columns
+}
+
+// field returns the field of the row at index i or an empty string if the row has fewer fields.
+func field(row []string, i int) string {
+	if i < len(row) {
+		return row[i]
+	}
+	return ""
+}
+
+// cmpBool compares booleans with false being ordered before true.
+func cmpBool(a, b bool) int {
+	if a == b {
+		return 0
+	}
+	if a {
+		return 1
+	}
+	return -1
+}
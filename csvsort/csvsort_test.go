@@ -0,0 +1,196 @@
+package csvsort
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// randomRows returns rows consisting of a string, a natural string, an integer, a float, a time and an index.
+// Few distinct values and some invalid fields make the following columns and the stability observable.
+func randomRows(n int) [][]string {
+	random := rand.New(rand.NewPCG(1, 2))
+	invalid := func(valid string) string {
+		switch random.IntN(10) {
+		case 0:
+			return ""
+		case 1:
+			return "n/a"
+		}
+		return valid
+	}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := make([][]string, n)
+	for i := range rows {
+		rows[i] = []string{
+			string(rune('a' + random.IntN(3))),
+			fmt.Sprintf("item%d", random.IntN(12)),
+			invalid(fmt.Sprint(random.IntN(7) - 3)),
+			invalid(fmt.Sprint(float64(random.IntN(9)-4) / 2)),
+			invalid(base.Add(time.Duration(random.IntN(5)-2) * 1500 * time.Millisecond).In(time.FixedZone("", 3600*random.IntN(2))).Format(time.RFC3339Nano)),
+			fmt.Sprint(i),
+		}
+	}
+	return rows
+}
+
+func TestSortRows(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Columns []Column
+	}{
+		{"string", []Column{{Index: 0}}},
+		{"string descending", []Column{{Index: 0, Descending: true}}},
+		{"natural", []Column{{Index: 1, Type: Natural}}},
+		{"int", []Column{{Index: 2, Type: Int}}},
+		{"int descending", []Column{{Index: 2, Type: Int, Descending: true}}},
+		{"float", []Column{{Index: 3, Type: Float}}},
+		{"float descending", []Column{{Index: 3, Type: Float, Descending: true}}},
+		{"time", []Column{{Index: 4, Type: Time}}},
+		{"time descending", []Column{{Index: 4, Type: Time, Descending: true}}},
+		{"multiple columns", []Column{{Index: 0, Descending: true}, {Index: 3, Type: Float}, {Index: 1, Type: Natural, Descending: true}, {Index: 2, Type: Int}}},
+		{"missing column", []Column{{Index: 8, Type: Int}, {Index: 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			rows := randomRows(2000)
+			want := slices.Clone(rows)
+			slices.SortStableFunc(want, Compare(tt.Columns))
+			if err := SortRows(rows, tt.Columns...); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.EqualFunc(rows, want, slices.Equal) {
+				t.Error("SortRows result differs from sorting stably using Compare")
+			}
+		})
+	}
+
+	// Sorting by one column after another is the same as sorting by all of them, starting with the last one
+	rows, want := randomRows(2000), randomRows(2000)
+	for _, c := range []Column{{Index: 2, Type: Int}, {Index: 4, Type: Time, Descending: true}, {Index: 1, Type: Natural}} {
+		if err := SortRows(rows, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	SortRows(want, Column{Index: 1, Type: Natural}, Column{Index: 4, Type: Time, Descending: true}, Column{Index: 2, Type: Int})
+	if !slices.EqualFunc(rows, want, slices.Equal) {
+		t.Error("successive SortRows calls differ from sorting by all columns")
+	}
+
+	rows = [][]string{{"b", "2"}, {"a", "3"}, {"b"}, {"a", "1"}}
+	if SortRows(rows); !slices.EqualFunc(rows, [][]string{{"a", "1"}, {"a", "3"}, {"b"}, {"b", "2"}}, slices.Equal) {
+		t.Errorf("SortRows without columns resulted in %q", rows)
+	}
+	rows = [][]string{{"5"}, {"-0"}, {"1e400"}, {"0"}, {" -1.5 "}, {"NaN"}, {"-inf"}}
+	if SortRows(rows, Column{Type: Float}); !slices.EqualFunc(rows, [][]string{{"-inf"}, {" -1.5 "}, {"-0"}, {"0"}, {"5"}, {"1e400"}, {"NaN"}}, slices.Equal) {
+		t.Errorf("SortRows by float resulted in %q", rows)
+	}
+}
+
+func TestSort(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Opts   Options
+		Inputs []string
+		Want   string
+	}{
+		{
+			"header",
+			Options{Header: true, Columns: []Column{{Name: "size", Type: Int, Descending: true}}},
+			[]string{"name,size\na,10\nb,9\nc,100\n"},
+			"name,size\nc,100\na,10\nb,9\n",
+		},
+		{
+			"header as data",
+			Options{Columns: []Column{{Index: 1}}},
+			[]string{"name,size\na,10\nb,9\n"},
+			"a,10\nb,9\nname,size\n",
+		},
+		{
+			"quoted multiline fields",
+			Options{Columns: []Column{{Index: 1, Type: Natural}}},
+			[]string{"\"line\nbreak\",item10\r\n\"a, \"\"quoted\"\" field\",item2\n"},
+			"\"a, \"\"quoted\"\" field\",item2\n\"line\nbreak\",item10\n",
+		},
+		{
+			"tsv",
+			Options{Comma: '\t', Header: true, Columns: []Column{{Name: "when", Type: Time, Layout: time.DateOnly}}},
+			[]string{"id\twhen\n1\t2024-03-01\n2\t2023-12-31\n3\t\n"},
+			"id\twhen\n2\t2023-12-31\n1\t2024-03-01\n3\t\n",
+		},
+		{
+			"multiple inputs",
+			Options{Header: true, Columns: []Column{{Index: 0, Type: Float}}},
+			[]string{"x\n2.5\n-1\n", "", "x\n0\n"},
+			"x\n-1\n0\n2.5\n",
+		},
+		{"empty", Options{Header: true, Columns: []Column{{Name: "x"}}}, []string{""}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var srcs []io.Reader
+			for _, in := range tt.Inputs {
+				srcs = append(srcs, strings.NewReader(in))
+			}
+			var out bytes.Buffer
+			if err := Sort(&out, tt.Opts, srcs...); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.Want {
+				t.Errorf("Sort resulted in %q, expected %q", out.String(), tt.Want)
+			}
+		})
+	}
+
+	errors := []struct {
+		Opts   Options
+		Inputs []string
+	}{
+		{Options{Columns: []Column{{Name: "x"}}}, []string{"x\n1\n"}},
+		{Options{Header: true, Columns: []Column{{Name: "y"}}}, []string{"x\n1\n"}},
+		{Options{Header: true}, []string{"x\n1\n", "y\n2\n"}},
+		{Options{Columns: []Column{{Index: -1}}}, []string{"1\n"}},
+		{Options{Columns: []Column{{Type: Time + 1}}}, []string{"1\n"}},
+		{Options{}, []string{"\"unterminated\n"}},
+	}
+	for _, tt := range errors {
+		var srcs []io.Reader
+		for _, in := range tt.Inputs {
+			srcs = append(srcs, strings.NewReader(in))
+		}
+		if err := Sort(&bytes.Buffer{}, tt.Opts, srcs...); err == nil {
+			t.Errorf("Sort accepted %q with options %+v", tt.Inputs, tt.Opts)
+		}
+	}
+}
+
+func TestParseColumn(t *testing.T) {
+	tests := []struct {
+		Input string
+		Want  Column
+		Valid bool
+	}{
+		{"1", Column{Index: 0}, true},
+		{"3:int:r", Column{Index: 2, Type: Int, Descending: true}, true},
+		{"created:time", Column{Name: "created", Type: Time}, true},
+		{"name::r", Column{Name: "name", Descending: true}, true},
+		{"2:natural", Column{Index: 1, Type: Natural}, true},
+		{"2:float:", Column{Index: 1, Type: Float}, true},
+		{"", Column{}, false},
+		{"0", Column{}, false},
+		{"1:number", Column{}, false},
+		{"1:int:x", Column{}, false},
+		{"1:int:r:r", Column{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseColumn(tt.Input)
+		if (err == nil) != tt.Valid || got != tt.Want {
+			t.Errorf("ParseColumn(%q) returned %+v, %v", tt.Input, got, err)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package deltaenc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"iter"
+)
+
+// Decode appends the integers encoded in src to dst.
+// An empty src is treated as an encoding without any integers.
+func Decode[T Integer](dst []T, src []byte) ([]T, error) {
+	d := NewDecoder[T](bytes.NewReader(src))
+	for {
+		v, err := d.Next()
+		if err == io.EOF {
+			return dst, nil
+		}
+		if err != nil {
+			return dst, err
+		}
+		dst = append(dst, v)
+	}
+}
+
+// Decoder reads encoded integers from a stream.
+type Decoder[T Integer] struct {
+	d   decoder
+	err error
+}
+
+// NewDecoder returns a decoder reading from r, which is buffered unless it implements io.ByteReader.
+func NewDecoder[T Integer](r io.Reader) *Decoder[T] {
+	return &Decoder[T]{d: newDecoder(r)}
+}
+
+// Next returns the next integer, or io.EOF if there are no more integers.
+// If the data is invalid or does not fit into T, ErrInvalid is returned, while data ending within an integer results in io.ErrUnexpectedEOF.
+func (dec *Decoder[T]) Next() (T, error) {
+	if dec.err != nil {
+		return 0, dec.err
+	}
+	k, err := dec.d.next()
+	if err != nil {
+		dec.err = err
+		return 0, err
+	}
+	v, ok := value[T](k)
+	if !ok {
+		dec.err = ErrInvalid
+		return 0, ErrInvalid
+	}
+	return v, nil
+}
+
+// All returns an iterator over the remaining integers, which stops after yielding the first error other than io.EOF.
+func (dec *Decoder[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			v, err := dec.Next()
+			if err == io.EOF || !yield(v, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// byteReader is implemented by bufio.Reader and bytes.Reader.
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// decoder reads the keys produced by encoder, keeping track of the previous key and the remaining keys of the current block of the Packed format.
+type decoder struct {
+	r      byteReader
+	header bool
+	format Format
+	prev   uint64
+	block  []uint64
+	packed []byte
+}
+
+func newDecoder(r io.Reader) decoder {
+	br, ok := r.(byteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return decoder{r: br}
+}
+
+// next returns the next key or io.EOF if there are no more keys.
+func (d *decoder) next() (uint64, error) {
+	if !d.header {
+		format, err := d.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if Format(format) > Packed {
+			return 0, ErrInvalid
+		}
+		d.header, d.format = true, Format(format)
+	}
+	if d.format == Packed && len(d.block) == 0 {
+		if err := d.readBlock(); err != nil {
+			return 0, err
+		}
+	}
+	var delta uint64
+	if d.format == Varint {
+		var err error
+		if delta, err = readUvarint(d.r); err != nil {
+			return 0, err
+		}
+	} else {
+		delta, d.block = d.block[0], d.block[1:]
+	}
+	if d.prev+delta < d.prev {
+		return 0, ErrInvalid
+	}
+	d.prev += delta
+	return d.prev, nil
+}
+
+// readBlock reads the deltas of the next block of the Packed format.
+func (d *decoder) readBlock() error {
+	n, err := readUvarint(d.r)
+	if err != nil {
+		return err
+	}
+	if n == 0 || n > BlockSize {
+		return ErrInvalid
+	}
+	first, err := readUvarint(d.r)
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	d.block = append(d.block[:0], first)
+	if n == 1 {
+		return nil
+	}
+	width, err := d.r.ReadByte()
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	if width > 64 {
+		return ErrInvalid
+	}
+	lowest, err := readUvarint(d.r)
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	size := (int(n-1)*int(width) + 7) / 8
+	d.packed = append(d.packed[:0], make([]byte, size)...)
+	if _, err := io.ReadFull(d.r, d.packed); err != nil {
+		return unexpectedEOF(err)
+	}
+
+	// Bits are read from every byte starting at the least significant one
+	pos, used := 0, 0
+	for range n - 1 {
+		var v uint64
+		for got := 0; got < int(width); {
+			n := min(8-used, int(width)-got)
+			v |= uint64(d.packed[pos]>>used&(1<<n-1)) << got
+			got += n
+			used += n
+			if used == 8 {
+				pos, used = pos+1, 0
+			}
+		}
+		if v+lowest < v {
+			return ErrInvalid
+		}
+		d.block = append(d.block, v+lowest)
+	}
+	return nil
+}
+
+// readUvarint reads a varint like binary.ReadUvarint, but reports varints overflowing a uint64 as ErrInvalid.
+func readUvarint(r io.ByteReader) (uint64, error) {
+	var x uint64
+	for i := 0; ; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			if i > 0 && err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		if i == binary.MaxVarintLen64-1 && b > 1 {
+			return 0, ErrInvalid
+		}
+		x |= uint64(b&0x7f) << (7 * i)
+		if b < 0x80 {
+			return x, nil
+		}
+	}
+}
+
+// unexpectedEOF maps io.EOF to io.ErrUnexpectedEOF for data within a block, where the end of the data is unexpected.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
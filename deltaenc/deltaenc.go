@@ -0,0 +1,217 @@
+// Package deltaenc implements a compact encoding of sorted integers, e.g. lists of IDs sorted using RadixSort.
+//
+// Instead of the integers themselves, only the differences between consecutive integers are stored, which are small for dense lists.
+// The differences are either written as varints or, for more compact results on large lists, packed into blocks using only as many bits
+// as the largest difference in the block requires after subtracting the smallest one (frame of reference).
+//
+// Encoded lists can be merged and intersected without decoding them into slices first.
+package deltaenc
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+var (
+	// ErrUnsorted is returned when encoding integers that are not sorted in ascending order.
+	ErrUnsorted = errors.New("deltaenc: integers not sorted")
+	// ErrInvalid is returned when decoding data that could not have been produced by the encoder.
+	ErrInvalid = errors.New("deltaenc: invalid encoding")
+)
+
+// Integer is the set of integer types that can be encoded.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Format selects how the differences between consecutive integers are encoded.
+type Format byte
+
+const (
+	// Varint encodes every difference as a varint, using a single byte for differences below 128.
+	Varint Format = iota
+	// Packed encodes blocks of up to BlockSize differences using the same number of bits for all differences of a block.
+	Packed
+)
+
+// BlockSize is the maximum number of integers in a block of the Packed format.
+const BlockSize = 128
+
+// Every encoding starts with a byte containing the format.
+// Varint is followed by the first integer and the differences to the previous integers as varints.
+// Packed is followed by blocks consisting of the number of integers and the first difference as varints.
+// Blocks of more than one integer continue with a byte containing the number of bits per difference,
+// the smallest of the remaining differences as a varint and the remaining differences minus the smallest one packed starting at the least significant bit.
+// Signed integers have their sign bit flipped, mapping them to unsigned integers in the same order, so the differences are never negative.
+// The number of integers is not encoded, so data truncated between two integers or blocks cannot be distinguished from an encoding of fewer integers.
+
+// Append appends the encoding of the sorted values to dst.
+// If the values are not sorted in ascending order, dst is returned unchanged along with an error wrapping ErrUnsorted.
+func Append[T Integer](dst []byte, values []T, format Format) ([]byte, error) {
+	if format > Packed {
+		return dst, fmt.Errorf("deltaenc: unknown format %d", format)
+	}
+	e := encoder{format: format}
+	out := append(dst, byte(format))
+	for i, v := range values {
+		var err error
+		if out, err = e.append(out, key(v)); err != nil {
+			return dst, fmt.Errorf("%w: %v at index %d is smaller than the previous integer", ErrUnsorted, v, i)
+		}
+	}
+	return e.flush(out), nil
+}
+
+// Encoder writes the encoding of sorted integers to a stream.
+type Encoder[T Integer] struct {
+	w   *bufio.Writer
+	e   encoder
+	buf []byte
+	err error
+}
+
+// NewEncoder returns an encoder writing to w in the given format, which is written to w when the encoder is closed at the latest.
+func NewEncoder[T Integer](w io.Writer, format Format) *Encoder[T] {
+	enc := &Encoder[T]{w: bufio.NewWriter(w), e: encoder{format: format}}
+	if format > Packed {
+		enc.err = fmt.Errorf("deltaenc: unknown format %d", format)
+	}
+	enc.buf = append(enc.buf, byte(format))
+	return enc
+}
+
+// Write encodes the values, which have to be sorted in ascending order and must not be smaller than the values written before.
+// If they are not, an error wrapping ErrUnsorted is returned and all further calls fail, while the values before the unsorted one have been encoded.
+func (enc *Encoder[T]) Write(values ...T) error {
+	for _, v := range values {
+		if enc.err != nil {
+			return enc.err
+		}
+		var err error
+		if enc.buf, err = enc.e.append(enc.buf, key(v)); err != nil {
+			enc.err = fmt.Errorf("%w: %v is smaller than the previous integer", ErrUnsorted, v)
+			return enc.err
+		}
+		if len(enc.buf) >= 4096 {
+			_, enc.err = enc.w.Write(enc.buf)
+			enc.buf = enc.buf[:0]
+		}
+	}
+	return enc.err
+}
+
+// Close writes the last block of the Packed format and flushes all data to the underlying writer, which is not closed.
+func (enc *Encoder[T]) Close() error {
+	if enc.err != nil {
+		return enc.err
+	}
+	enc.buf = enc.e.flush(enc.buf)
+	if _, err := enc.w.Write(enc.buf); err != nil {
+		return err
+	}
+	enc.buf = enc.buf[:0]
+	return enc.w.Flush()
+}
+
+// encoder appends the encoding of keys to byte slices, keeping track of the previous key and the pending block of the Packed format.
+type encoder struct {
+	format Format
+	prev   uint64
+	block  []uint64
+}
+
+// append appends the encoding of k to dst, returning ErrUnsorted if it is smaller than the previous key.
+// Since the first key is encoded as the difference to 0, it does not need special treatment.
+func (e *encoder) append(dst []byte, k uint64) ([]byte, error) {
+	if k < e.prev {
+		return dst, ErrUnsorted
+	}
+	delta := k - e.prev
+	e.prev = k
+	if e.format == Varint {
+		return binary.AppendUvarint(dst, delta), nil
+	}
+	e.block = append(e.block, delta)
+	if len(e.block) == BlockSize {
+		dst = appendBlock(dst, e.block)
+		e.block = e.block[:0]
+	}
+	return dst, nil
+}
+
+// flush appends the pending block of the Packed format to dst.
+func (e *encoder) flush(dst []byte) []byte {
+	if len(e.block) > 0 {
+		dst = appendBlock(dst, e.block)
+		e.block = e.block[:0]
+	}
+	return dst
+}
+
+// appendBlock appends a block of the Packed format containing the deltas to dst.
+func appendBlock(dst []byte, deltas []uint64) []byte {
+	dst = binary.AppendUvarint(dst, uint64(len(deltas)))
+	dst = binary.AppendUvarint(dst, deltas[0])
+	rest := deltas[1:]
+	if len(rest) == 0 {
+		return dst
+	}
+	lowest, highest := rest[0], rest[0]
+	for _, d := range rest {
+		lowest, highest = min(lowest, d), max(highest, d)
+	}
+	width := bits.Len64(highest - lowest)
+	dst = append(dst, byte(width))
+	dst = binary.AppendUvarint(dst, lowest)
+
+	// Bits are filled into every byte starting at the least significant one
+	var cur byte
+	var used int
+	for _, d := range rest {
+		v := d - lowest
+		for remaining := width; remaining > 0; {
+			cur |= byte(v << used)
+			n := min(8-used, remaining)
+			v >>= n
+			remaining -= n
+			used += n
+			if used == 8 {
+				dst = append(dst, cur)
+				cur, used = 0, 0
+			}
+		}
+	}
+	if used > 0 {
+		dst = append(dst, cur)
+	}
+	return dst
+}
+
+// key maps an integer to an unsigned integer with the same order by flipping the sign bit of signed integers.
+func key[T Integer](v T) uint64 {
+	if signed[T]() {
+		return uint64(int64(v)) ^ 1<<63
+	}
+	return uint64(v)
+}
+
+// value is the inverse of key, reporting whether the key is within the range of T.
+func value[T Integer](k uint64) (T, bool) {
+	var v T
+	if signed[T]() {
+		v = T(int64(k ^ 1<<63))
+	} else {
+		v = T(k)
+	}
+	return v, key(v) == k
+}
+
+// signed reports whether T is a signed integer type.
+func signed[T Integer]() bool {
+	var zero T
+	return zero-1 < zero
+}
@@ -0,0 +1,200 @@
+package deltaenc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	sort "github.com/fossoreslp/go-sort"
+)
+
+func randomSorted(random *rand.Rand, n int, spread uint64) []uint64 {
+	values := make([]uint64, n)
+	for i := range values {
+		values[i] = random.Uint64N(spread)
+	}
+	return sort.RadixSort(values)
+}
+
+func roundTrip[T Integer](t *testing.T, values []T) {
+	t.Helper()
+	for _, format := range []Format{Varint, Packed} {
+		encoded, err := Append(nil, values, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := Decode[T](nil, encoded)
+		if err != nil || !slices.Equal(decoded, values) {
+			t.Errorf("decoding %v in format %d returned %v, %v", values, format, decoded, err)
+		}
+
+		var buf bytes.Buffer
+		enc := NewEncoder[T](&buf, format)
+		for chunk := range slices.Chunk(values, 100) {
+			if err := enc.Write(chunk...); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), encoded) {
+			t.Errorf("Encoder result differs from Append in format %d", format)
+		}
+		decoded = decoded[:0]
+		for v, err := range NewDecoder[T](iotestReader{&buf}).All() {
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded = append(decoded, v)
+		}
+		if !slices.Equal(decoded, values) {
+			t.Errorf("Decoder returned different integers in format %d", format)
+		}
+	}
+}
+
+// iotestReader hides all methods but Read, so the Decoder has to buffer it.
+type iotestReader struct {
+	r io.Reader
+}
+
+func (r iotestReader) Read(p []byte) (int, error) {
+	return r.r.Read(p)
+}
+
+func TestRoundTrip(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	for _, n := range []int{0, 1, 2, 127, 128, 129, 1000} {
+		roundTrip(t, randomSorted(random, n, 1<<20))
+		roundTrip(t, randomSorted(random, n, math.MaxUint64))
+	}
+	roundTrip(t, []uint64{0, 0, 0, math.MaxUint64, math.MaxUint64})
+	roundTrip(t, []int64{math.MinInt64, -5, -5, 0, 3, math.MaxInt64})
+	roundTrip(t, []int8{-128, -1, 0, 127})
+	roundTrip(t, []uint16{1, 2, 3, 65535})
+}
+
+func TestCompression(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	// IDs with an average distance of 16 almost always need a single byte as varints and fewer bits when packed
+	values := randomSorted(random, 100000, 16*100000)
+	varint, _ := Append(nil, values, Varint)
+	packed, _ := Append(nil, values, Packed)
+	if len(varint) > len(values)*11/10 {
+		t.Errorf("varint encoding of %d integers uses %d bytes", len(values), len(varint))
+	}
+	if len(packed) >= len(varint) {
+		t.Errorf("packed encoding of %d integers uses %d bytes", len(values), len(packed))
+	}
+}
+
+func TestUnsorted(t *testing.T) {
+	dst := []byte{42}
+	for _, format := range []Format{Varint, Packed} {
+		got, err := Append(dst, []int{1, 3, 2}, format)
+		if !errors.Is(err, ErrUnsorted) || !bytes.Equal(got, dst) {
+			t.Errorf("Append of unsorted integers returned %v, %v", got, err)
+		}
+		enc := NewEncoder[int](io.Discard, format)
+		if err := enc.Write(1, 3); err != nil {
+			t.Fatal(err)
+		}
+		if err := enc.Write(2); !errors.Is(err, ErrUnsorted) {
+			t.Errorf("Encoder.Write of an unsorted integer returned %v", err)
+		}
+		if err := enc.Close(); !errors.Is(err, ErrUnsorted) {
+			t.Errorf("Encoder.Close after an unsorted integer returned %v", err)
+		}
+	}
+	if _, err := Append(nil, []int{1}, 2); err == nil {
+		t.Error("Append accepted an unknown format")
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	values := []uint64{1, 300, 70000, 70001, 1 << 40}
+	for _, format := range []Format{Varint, Packed} {
+		encoded, _ := Append(nil, values, format)
+		// Data ending between two integers or blocks is indistinguishable from an encoding of fewer integers
+		for i := 1; i < len(encoded); i++ {
+			got, err := Decode[uint64](nil, encoded[:i])
+			if err != io.ErrUnexpectedEOF && !(err == nil && len(got) < len(values) && slices.Equal(got, values[:len(got)])) {
+				t.Errorf("decoding %d of %d bytes in format %d returned %v, %v", i, len(encoded), format, got, err)
+			}
+		}
+		if _, err := Decode[uint32](nil, encoded); err != ErrInvalid {
+			t.Errorf("decoding integers beyond the range of the type in format %d returned %v", format, err)
+		}
+	}
+	invalid := [][]byte{
+		{2},
+		{byte(Varint), 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x7F},
+		{byte(Varint), 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01, 0x01},
+		{byte(Packed), 0},
+		{byte(Packed), 129, 1},
+		{byte(Packed), 2, 0, 65, 0},
+	}
+	for _, data := range invalid {
+		if _, err := Decode[uint64](nil, data); err != ErrInvalid {
+			t.Errorf("decoding %v returned %v", data, err)
+		}
+	}
+	if got, err := Decode[uint64](nil, nil); err != nil || len(got) != 0 {
+		t.Errorf("decoding empty data returned %v, %v", got, err)
+	}
+}
+
+func TestMergeIntersect(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	a := randomSorted(random, 1000, 3000)
+	b := randomSorted(random, 700, 3000)
+	wantMerge := sort.MergeSortedSets(a, b)
+	var wantIntersect []uint64
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case b[j] < a[i]:
+			j++
+		default:
+			wantIntersect = append(wantIntersect, a[i])
+			i, j = i+1, j+1
+		}
+	}
+
+	encA, _ := Append(nil, a, Packed)
+	encB, _ := Append(nil, b, Varint)
+	for _, format := range []Format{Varint, Packed} {
+		merged, err := Merge(encA, encB, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := Decode[uint64](nil, merged); !slices.Equal(got, wantMerge) {
+			t.Errorf("Merge in format %d differs from MergeSortedSets", format)
+		}
+		intersected, err := Intersect(encA, encB, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := Decode[uint64](nil, intersected); !slices.Equal(got, wantIntersect) {
+			t.Errorf("Intersect in format %d returned %v, expected %v", format, got, wantIntersect)
+		}
+	}
+	if _, err := Intersect(encA, encA[:len(encA)-1], Varint); err != io.ErrUnexpectedEOF {
+		t.Errorf("Intersect with truncated data returned %v", err)
+	}
+	if got, err := Merge(nil, encB, Varint); err != nil || !bytes.Equal(got, encB) {
+		t.Errorf("Merge with empty data returned %v", err)
+	}
+	if got, err := Merge(encA, encA, Format(7)); err == nil {
+		t.Errorf("Merge accepted an unknown format, returning %v", got)
+	}
+	if got, err := Intersect(encA, encA, Format(7)); err == nil {
+		t.Errorf("Intersect accepted an unknown format, returning %v", got)
+	}
+}
@@ -0,0 +1,73 @@
+package deltaenc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Merge merges two encoded lists of integers into a single encoded list in the given format, keeping duplicates just like sort.MergeSortedSets.
+// The integers are decoded one after the other without creating slices of them, but both lists have to be encoded from the same integer type.
+func Merge(a, b []byte, format Format) ([]byte, error) {
+	return combine(a, b, format, true)
+}
+
+// Intersect returns the encoded list of integers contained in both encoded lists in the given format.
+// Integers contained multiple times in both lists are contained as many times as in the list containing them less often.
+// Like Merge, both lists have to be encoded from the same integer type.
+func Intersect(a, b []byte, format Format) ([]byte, error) {
+	return combine(a, b, format, false)
+}
+
+// combine merges or intersects the encoded lists a and b.
+func combine(a, b []byte, format Format, merge bool) ([]byte, error) {
+	if format > Packed {
+		return nil, fmt.Errorf("deltaenc: unknown format %d", format)
+	}
+	da, db := newDecoder(bytes.NewReader(a)), newDecoder(bytes.NewReader(b))
+	ka, errA := da.next()
+	kb, errB := db.next()
+	e := encoder{format: format}
+	dst := []byte{byte(format)}
+	for errA == nil && errB == nil {
+		switch {
+		case ka < kb:
+			if merge {
+				dst, _ = e.append(dst, ka)
+			}
+			ka, errA = da.next()
+		case kb < ka:
+			if merge {
+				dst, _ = e.append(dst, kb)
+			}
+			kb, errB = db.next()
+		default:
+			dst, _ = e.append(dst, ka)
+			if merge {
+				dst, _ = e.append(dst, kb)
+			}
+			ka, errA = da.next()
+			kb, errB = db.next()
+		}
+	}
+	// The rest of the remaining list is only part of a merge, but has to be checked for errors either way
+	for errA == nil {
+		if merge {
+			dst, _ = e.append(dst, ka)
+		}
+		ka, errA = da.next()
+	}
+	for errB == nil {
+		if merge {
+			dst, _ = e.append(dst, kb)
+		}
+		kb, errB = db.next()
+	}
+	if errA != io.EOF {
+		return nil, errA
+	}
+	if errB != io.EOF {
+		return nil, errB
+	}
+	return e.flush(dst), nil
+}
@@ -0,0 +1,129 @@
+package sort
+
+import (
+	"cmp"
+	"slices"
+	"unsafe"
+)
+
+// RadixSortDesc is the equivalent of RadixSort that sorts in descending order.
+// Instead of reversing the result, the bits of all values are inverted before and after sorting, which keeps equal values in their original order.
+// Other data types such as float64, float32 and string are handled via a fallback to slices.SortFunc.
+func RadixSortDesc[T cmp.Ordered](items []T) []T {
+	// No need to sort slices with less than two items
+	if len(items) < 2 {
+		return items
+	}
+	switch s := any(items).(type) {
+	case []uint64:
+		radixSortXor(s, ^uint64(0))
+	case []uint32:
+		radixSortXor(s, ^uint32(0))
+	case []uint16:
+		radixSortXor(s, ^uint16(0))
+	case []uint8:
+		radixSortXor(s, ^uint8(0))
+	case []uint:
+		radixSortXor(s, ^uint(0))
+	case []uintptr:
+		radixSortXor(s, ^uintptr(0))
+	// Signed integers need their sign bit flipped, inverting all other bits is the same as flipping the sign bit and then inverting all bits
+	case []int64:
+		radixSortXor[int64, uint64](s, 0x7FFFFFFFFFFFFFFF)
+	case []int32:
+		radixSortXor[int32, uint32](s, 0x7FFFFFFF)
+	case []int16:
+		radixSortXor[int16, uint16](s, 0x7FFF)
+	case []int8:
+		radixSortXor[int8, uint8](s, 0x7F)
+	case []int:
+		radixSortXor[int, uint](s, ^uint(0)>>1)
+	default:
+		slices.SortFunc(items, func(a, b T) int { return cmp.Compare(b, a) })
+	}
+	return items
+}
+
+// radixSortXor reinterprets integers as unsigned integers of the same size and sorts them after applying mask using XOR.
+// The mask is applied again afterwards to restore the original values.
+func radixSortXor[S uint64 | uint32 | uint16 | uint8 | uint | uintptr | int64 | int32 | int16 | int8 | int, U uint64 | uint32 | uint16 | uint8 | uint | uintptr](items []S, mask U) {
+	uintslice := unsafe.Slice((*U)(unsafe.Pointer(unsafe.SliceData(items))), len(items))
+	for i := range uintslice {
+		uintslice[i] ^= mask
+	}
+	switch u := any(uintslice).(type) {
+	case []uint8:
+		countingSort(u)
+	case []uint16:
+		radixSortUint(u, nil, nil)
+	case []uint32:
+		radixSortUint(u, nil, nil)
+	case []uint64:
+		radixSortUint(u, nil, nil)
+	case []uint:
+		radixSortUint(u, nil, nil)
+	case []uintptr:
+		radixSortUint(u, nil, nil)
+	}
+	for i := range uintslice {
+		uintslice[i] ^= mask
+	}
+}
+
+// MergeSortDesc is the equivalent of MergeSort that sorts in descending order.
+// The comparison is reversed while still preferring the earlier element when two are equal, so the sort remains stable.
+func MergeSortDesc[T cmp.Ordered](items []T) []T {
+	if len(items) < 2 {
+		return items
+	}
+
+	// Create a copy of the data since merge sort cannot easily operate in-place
+	tmp := make([]T, len(items))
+	copy(tmp, items)
+
+	// Sort with alternating source and destination
+	mergeSortDesc(tmp, items)
+
+	return items
+}
+
+// mergeSortDesc is the equivalent of mergeSort for descending order.
+func mergeSortDesc[T cmp.Ordered](src, dst []T) {
+	if len(src) < 2 {
+		return
+	}
+
+	// Find the midpoint
+	mid := len(src) / 2
+
+	// Recursively sort the two halves with swapped src and dst
+	mergeSortDesc(dst[:mid], src[:mid])
+	mergeSortDesc(dst[mid:], src[mid:])
+
+	// Merge the sorted halves from src into dst
+	mergeSortedSetsDesc(src[:mid], src[mid:], dst)
+}
+
+// mergeSortedSetsDesc is the equivalent of mergeSortedSets for slices sorted in descending order.
+func mergeSortedSetsDesc[T cmp.Ordered](a, b []T, buf []T) {
+	length := len(a) + len(b)
+	aPos := 0
+	bPos := 0
+	for i := range length {
+		if !less(a[aPos], b[bPos]) {
+			buf[i] = a[aPos]
+			aPos++
+			if aPos == len(a) {
+				copy(buf[i+1:], b[bPos:])
+				return
+			}
+		} else {
+			buf[i] = b[bPos]
+			bPos++
+			if bPos == len(b) {
+				copy(buf[i+1:], a[aPos:])
+				return
+			}
+		}
+	}
+}
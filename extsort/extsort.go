@@ -0,0 +1,425 @@
+// Package extsort implements external sorting for data that does not fit into memory.
+//
+// Items are collected in memory until a limit is reached, then sorted and written to a temporary file called a run.
+// Afterwards the runs are merged, which only requires a small buffer per run.
+// All sorts are stable, so items comparing equal retain the order in which they were added.
+package extsort
+
+import (
+	"bufio"
+	"container/heap"
+	"errors"
+	"io"
+	"iter"
+	"os"
+	"slices"
+	"unsafe"
+
+	sort "github.com/fossoreslp/go-sort"
+	"github.com/fossoreslp/go-sort/internal/progress"
+)
+
+// Codec writes items to runs and reads them back.
+type Codec[T any] interface {
+	// Encode writes a single item.
+	Encode(w *bufio.Writer, item T) error
+	// Decode reads a single item, returning io.EOF if there are no more items.
+	Decode(r *bufio.Reader) (T, error)
+}
+
+// Options configures a Sorter. The zero value uses the defaults for all options.
+type Options[T any] struct {
+	// Size returns the approximate number of bytes of memory used by an item.
+	// It defaults to the size of T, which is only correct if T contains no pointers.
+	Size func(T) int
+	// MemoryLimit is the approximate number of bytes of items kept in memory before they are written to a run.
+	// It defaults to DefaultMemoryLimit.
+	MemoryLimit int
+	// MaxFanIn is the maximum number of runs merged at once, limiting the number of open files.
+	// If there are more runs, they are merged in multiple passes. It defaults to DefaultMaxFanIn.
+	MaxFanIn int
+	// TempDir is the directory in which the runs are created. It defaults to os.TempDir.
+	TempDir string
+	// Sort sorts the items in memory, e.g. using radix sort on keys known to the caller.
+	// It has to be stable and produce the same order as the comparison function. It defaults to sort.SortStableFunc.
+	Sort func(items []T)
+	// Progress is called with the completed fraction after every spilled run, every merged group of runs and during the final merge,
+	// limited to steps of at least one percent like the callbacks registered using sort.WithProgress.
+	// The work is measured by the size of the items written to and read from runs.
+	Progress func(fraction float64)
+	// ExpectedSize is the approximate total size of all items as returned by Size, which is used to estimate the progress while items are added.
+	// Without it, the total is only known once Each is called, so spilled runs report less meaningful fractions.
+	ExpectedSize int
+}
+
+const (
+	// DefaultMemoryLimit is the memory limit used if Options.MemoryLimit is not set.
+	DefaultMemoryLimit = 64 << 20
+	// DefaultMaxFanIn is the maximum number of runs merged at once if Options.MaxFanIn is not set.
+	DefaultMaxFanIn = 64
+)
+
+// Sorter sorts items that may not fit into memory.
+// Items are added using Add and retrieved in sorted order using Each.
+// Close has to be called to remove the temporary files once the Sorter is no longer needed.
+type Sorter[T any] struct {
+	compare func(a, b T) int
+	codec   Codec[T]
+	opts    Options[T]
+	items   []T
+	used    int
+	dir     string
+	runs    []string
+	// sizes holds the total size of the items in each run and spilled the size of all items written to runs by spill
+	sizes    []int
+	spilled  int
+	progress *progress.Progress
+}
+
+// New returns a Sorter ordering items using compare and writing them to runs using codec.
+func New[T any](compare func(a, b T) int, codec Codec[T], opts Options[T]) *Sorter[T] {
+	if opts.Size == nil {
+		var zero T
+		size := int(unsafe.Sizeof(zero))
+		opts.Size = func(T) int { return size }
+	}
+	if opts.MemoryLimit <= 0 {
+		opts.MemoryLimit = DefaultMemoryLimit
+	}
+	if opts.MaxFanIn < 2 {
+		opts.MaxFanIn = DefaultMaxFanIn
+	}
+	if opts.Sort == nil {
+		opts.Sort = func(items []T) { sort.SortStableFunc(items, compare) }
+	}
+	return &Sorter[T]{compare: compare, codec: codec, opts: opts, progress: progress.New(opts.Progress, 0)}
+}
+
+// Add adds an item, writing all items in memory to a new run if the memory limit is reached.
+func (s *Sorter[T]) Add(item T) error {
+	s.items = append(s.items, item)
+	s.used += s.opts.Size(item)
+	if s.used >= s.opts.MemoryLimit {
+		return s.spill()
+	}
+	return nil
+}
+
+// Runs returns the number of runs written so far.
+func (s *Sorter[T]) Runs() int {
+	return len(s.runs)
+}
+
+// Each calls fn for all items in sorted order, stopping at the first error, which is returned.
+// Items comparing equal are passed in the order they were added.
+// Afterwards the Sorter is empty and can be reused.
+func (s *Sorter[T]) Each(fn func(T) error) error {
+	s.opts.Sort(s.items)
+	if len(s.runs) == 0 {
+		for _, item := range s.items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		s.reset()
+		s.finishProgress()
+		return nil
+	}
+
+	// Now that all items are known, the total consists of the spilled items, the groups merged in multiple passes and the final merge
+	s.progress.SetTotal(float64(s.spilled + s.mergeWork() + s.spilled + s.used))
+
+	// Merge groups of runs into larger runs until all of them can be merged at once along with the items in memory
+	for len(s.runs)+1 > s.opts.MaxFanIn {
+		var merged []string
+		var sizes []int
+		for start := 0; start < len(s.runs); start += s.opts.MaxFanIn {
+			end := min(start+s.opts.MaxFanIn, len(s.runs))
+			size := 0
+			for _, n := range s.sizes[start:end] {
+				size += n
+			}
+			sizes = append(sizes, size)
+			if end-start == 1 {
+				merged = append(merged, s.runs[start])
+				continue
+			}
+			run, err := s.mergeRuns(s.runs[start:end])
+			if err != nil {
+				return err
+			}
+			merged = append(merged, run)
+			s.progress.Add(float64(size))
+		}
+		s.runs, s.sizes = merged, sizes
+	}
+
+	inputs := make([]iter.Seq2[T, error], 0, len(s.runs)+1)
+	for _, run := range s.runs {
+		inputs = append(inputs, s.readRun(run))
+	}
+	inputs = append(inputs, func(yield func(T, error) bool) {
+		for _, item := range s.items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	})
+	// The final merge is reported per item since it usually is the largest part of the work
+	if s.progress != nil {
+		yield := fn
+		fn = func(item T) error {
+			s.progress.Add(float64(s.opts.Size(item)))
+			return yield(item)
+		}
+	}
+	if err := Merge(s.compare, fn, inputs...); err != nil {
+		return err
+	}
+	if err := s.removeRuns(); err != nil {
+		return err
+	}
+	s.finishProgress()
+	return nil
+}
+
+// mergeWork returns the total size of the groups of runs Each merges in multiple passes before the final merge.
+func (s *Sorter[T]) mergeWork() int {
+	sizes, work := s.sizes, 0
+	for len(sizes)+1 > s.opts.MaxFanIn {
+		var merged []int
+		for group := range slices.Chunk(sizes, s.opts.MaxFanIn) {
+			size := 0
+			for _, n := range group {
+				size += n
+			}
+			if len(group) > 1 {
+				work += size
+			}
+			merged = append(merged, size)
+		}
+		sizes = merged
+	}
+	return work
+}
+
+// finishProgress reports the completion of Each and starts tracking the progress of the next use of the Sorter.
+func (s *Sorter[T]) finishProgress() {
+	s.progress.Finish()
+	s.progress = progress.New(s.opts.Progress, 0)
+	s.spilled = 0
+}
+
+// Close removes all temporary files.
+func (s *Sorter[T]) Close() error {
+	s.items = nil
+	if s.dir == "" {
+		return nil
+	}
+	err := os.RemoveAll(s.dir)
+	s.dir, s.runs, s.sizes = "", nil, nil
+	return err
+}
+
+// reset clears the items in memory for the Sorter to be reused.
+func (s *Sorter[T]) reset() {
+	clear(s.items)
+	s.items = s.items[:0]
+	s.used = 0
+}
+
+// spill sorts the items in memory and writes them to a new run.
+func (s *Sorter[T]) spill() error {
+	s.opts.Sort(s.items)
+	run, err := s.writeRun(func(yield func(T) error) error {
+		for _, item := range s.items {
+			if err := yield(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, run)
+	s.sizes = append(s.sizes, s.used)
+
+	// Every spilled item is written once and read once by the final merge, ignoring merge passes that are not known yet
+	s.spilled += s.used
+	s.progress.SetTotal(float64(2 * max(s.opts.ExpectedSize, s.spilled)))
+	s.progress.Add(float64(s.used))
+	s.reset()
+	return nil
+}
+
+// mergeRuns merges the given runs into a new run, removing them afterwards.
+func (s *Sorter[T]) mergeRuns(runs []string) (string, error) {
+	inputs := make([]iter.Seq2[T, error], len(runs))
+	for i, run := range runs {
+		inputs[i] = s.readRun(run)
+	}
+	merged, err := s.writeRun(func(yield func(T) error) error { return Merge(s.compare, yield, inputs...) })
+	if err != nil {
+		return "", err
+	}
+	for _, run := range runs {
+		if err := os.Remove(run); err != nil {
+			return "", err
+		}
+	}
+	return merged, nil
+}
+
+// writeRun creates a new run containing the items passed to yield by items and returns its path.
+// Runs are created in a temporary directory, which is created for the first run and removed by Close.
+func (s *Sorter[T]) writeRun(items func(yield func(T) error) error) (run string, err error) {
+	if s.dir == "" {
+		if s.dir, err = os.MkdirTemp(s.opts.TempDir, "extsort-"); err != nil {
+			return "", err
+		}
+	}
+	f, err := os.CreateTemp(s.dir, "run-")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		err = errors.Join(err, f.Close())
+	}()
+	w := bufio.NewWriter(f)
+	if err := items(func(item T) error { return s.codec.Encode(w, item) }); err != nil {
+		return f.Name(), err
+	}
+	return f.Name(), w.Flush()
+}
+
+// readRun returns an iterator over the items of a run.
+func (s *Sorter[T]) readRun(run string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		f, err := os.Open(run)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer f.Close()
+		r := bufio.NewReader(f)
+		for {
+			item, err := s.codec.Decode(r)
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+// removeRuns removes all runs after they have been merged.
+func (s *Sorter[T]) removeRuns() error {
+	for _, run := range s.runs {
+		if err := os.Remove(run); err != nil {
+			return err
+		}
+	}
+	s.runs, s.sizes = nil, nil
+	s.reset()
+	return nil
+}
+
+// Merge merges sorted inputs, calling fn for every item in sorted order and stopping at the first error, which is returned.
+// Items comparing equal are passed in the order of the inputs they are from, so merging is stable.
+func Merge[T any](compare func(a, b T) int, fn func(T) error, inputs ...iter.Seq2[T, error]) error {
+	h := &mergeHeap[T]{compare: compare}
+	defer func() {
+		for _, c := range h.cursors {
+			c.stop()
+		}
+	}()
+	for i, input := range inputs {
+		next, stop := iter.Pull2(input)
+		c := &cursor[T]{index: i, next: next, stop: stop}
+		ok, err := c.advance()
+		if err != nil {
+			stop()
+			return err
+		}
+		if ok {
+			h.cursors = append(h.cursors, c)
+		} else {
+			stop()
+		}
+	}
+	heap.Init(h)
+	for len(h.cursors) > 0 {
+		c := h.cursors[0]
+		if err := fn(c.item); err != nil {
+			return err
+		}
+		ok, err := c.advance()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			c.stop()
+			heap.Pop(h)
+		}
+	}
+	return nil
+}
+
+// cursor holds the current item of an input of Merge.
+type cursor[T any] struct {
+	index int
+	item  T
+	next  func() (T, error, bool)
+	stop  func()
+}
+
+// advance reads the next item, returning false if the input is exhausted.
+func (c *cursor[T]) advance() (bool, error) {
+	item, err, ok := c.next()
+	if !ok {
+		return false, nil
+	}
+	c.item = item
+	return true, err
+}
+
+// mergeHeap implements heap.Interface for the cursors of Merge, ordering them by their current item and the index of their input.
+type mergeHeap[T any] struct {
+	compare func(a, b T) int
+	cursors []*cursor[T]
+}
+
+func (h *mergeHeap[T]) Len() int {
+	return len(h.cursors)
+}
+
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	if c := h.compare(h.cursors[i].item, h.cursors[j].item); c != 0 {
+		return c < 0
+	}
+	return h.cursors[i].index < h.cursors[j].index
+}
+
+func (h *mergeHeap[T]) Swap(i, j int) {
+	h.cursors[i], h.cursors[j] = h.cursors[j], h.cursors[i]
+}
+
+func (h *mergeHeap[T]) Push(x any) {
+	h.cursors = append(h.cursors, x.(*cursor[T]))
+}
+
+func (h *mergeHeap[T]) Pop() any {
+	c := h.cursors[len(h.cursors)-1]
+	h.cursors = h.cursors[:len(h.cursors)-1]
+	return c
+}
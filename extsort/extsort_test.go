@@ -0,0 +1,207 @@
+package extsort
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"io"
+	"iter"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"testing"
+)
+
+type item struct {
+	Key   int64
+	Index int64
+}
+
+type itemCodec struct{}
+
+func (itemCodec) Encode(w *bufio.Writer, v item) error {
+	w.Write(binary.AppendVarint(nil, v.Key))
+	_, err := w.Write(binary.AppendVarint(nil, v.Index))
+	return err
+}
+
+func (itemCodec) Decode(r *bufio.Reader) (item, error) {
+	key, err := binary.ReadVarint(r)
+	if err != nil {
+		return item{}, err
+	}
+	index, err := binary.ReadVarint(r)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return item{key, index}, err
+}
+
+func compareItems(a, b item) int {
+	return cmp.Compare(a.Key, b.Key)
+}
+
+func randomItems(n int) []item {
+	random := rand.New(rand.NewPCG(1, 2))
+	items := make([]item, n)
+	for i := range items {
+		// Few distinct keys make the stability observable
+		items[i] = item{random.Int64N(100) - 50, int64(i)}
+	}
+	return items
+}
+
+func TestSorter(t *testing.T) {
+	tests := []struct {
+		Name     string
+		N        int
+		Options  Options[item]
+		WantRuns bool
+	}{
+		{"empty", 0, Options[item]{}, false},
+		{"in memory", 1000, Options[item]{}, false},
+		{"single pass merge", 1000, Options[item]{MemoryLimit: 100 * 16}, true},
+		{"multi pass merge", 1000, Options[item]{MemoryLimit: 30 * 16, MaxFanIn: 2}, true},
+		{"custom size", 1000, Options[item]{MemoryLimit: 1000, Size: func(item) int { return 10 }, MaxFanIn: 3}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.Options.TempDir = t.TempDir()
+			s := New(compareItems, itemCodec{}, tt.Options)
+			defer s.Close()
+			items := randomItems(tt.N)
+			for _, v := range items {
+				if err := s.Add(v); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := s.Runs() > 0; got != tt.WantRuns {
+				t.Errorf("Sorter wrote runs: %t, expected %t", got, tt.WantRuns)
+			}
+			var got []item
+			if err := s.Each(func(v item) error {
+				got = append(got, v)
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			want := slices.Clone(items)
+			slices.SortStableFunc(want, compareItems)
+			if !slices.Equal(got, want) {
+				t.Errorf("Sorter result is not sorted stably")
+			}
+			if err := s.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if entries, _ := os.ReadDir(tt.Options.TempDir); len(entries) != 0 {
+				t.Errorf("Sorter left %d temporary files", len(entries))
+			}
+		})
+	}
+}
+
+func TestSorterProgress(t *testing.T) {
+	items := randomItems(1000)
+	tests := []struct {
+		Name    string
+		Options Options[item]
+	}{
+		{"in memory", Options[item]{}},
+		{"single pass merge", Options[item]{MemoryLimit: 100 * 16}},
+		{"multi pass merge", Options[item]{MemoryLimit: 30 * 16, MaxFanIn: 2}},
+		{"expected size", Options[item]{MemoryLimit: 30 * 16, MaxFanIn: 4, ExpectedSize: len(items) * 16}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var fractions []float64
+			tt.Options.TempDir = t.TempDir()
+			tt.Options.Progress = func(f float64) { fractions = append(fractions, f) }
+			s := New(compareItems, itemCodec{}, tt.Options)
+			defer s.Close()
+			// The Sorter is used twice to check that the progress starts over
+			for range 2 {
+				fractions = fractions[:0]
+				for _, v := range items {
+					if err := s.Add(v); err != nil {
+						t.Fatal(err)
+					}
+				}
+				spilled, runs := len(fractions), s.Runs()
+				if err := s.Each(func(item) error { return nil }); err != nil {
+					t.Fatal(err)
+				}
+				if len(fractions) == 0 || fractions[len(fractions)-1] != 1 || !slices.IsSorted(fractions) {
+					t.Errorf("Sorter reported fractions %v", fractions)
+				}
+				if tt.Options.ExpectedSize > 0 && spilled < 10 {
+					t.Errorf("Sorter reported only %d fractions while spilling runs", spilled)
+				}
+				if runs > 0 && len(fractions) < 3 {
+					t.Errorf("Sorter reported only fractions %v for %d runs", fractions, runs)
+				}
+			}
+		})
+	}
+}
+
+func TestSorterError(t *testing.T) {
+	s := New(compareItems, itemCodec{}, Options[item]{MemoryLimit: 10 * 16, TempDir: t.TempDir()})
+	defer s.Close()
+	for _, v := range randomItems(100) {
+		if err := s.Add(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	errStop := errors.New("stop")
+	calls := 0
+	err := s.Each(func(item) error {
+		calls++
+		return errStop
+	})
+	if err != errStop || calls != 1 {
+		t.Errorf("Each returned %v after %d calls, expected %v after 1 call", err, calls, errStop)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	inputs := make([][]item, 5)
+	var want []item
+	for i, v := range randomItems(500) {
+		inputs[i%len(inputs)] = append(inputs[i%len(inputs)], v)
+	}
+	seqs := make([]iter.Seq2[item, error], len(inputs))
+	for i := range inputs {
+		slices.SortStableFunc(inputs[i], compareItems)
+		want = append(want, inputs[i]...)
+		seqs[i] = func(yield func(item, error) bool) {
+			for _, v := range inputs[i] {
+				if !yield(v, nil) {
+					return
+				}
+			}
+		}
+	}
+	// Items from earlier inputs are ordered first if their keys are equal
+	slices.SortStableFunc(want, compareItems)
+
+	var got []item
+	if err := Merge(compareItems, func(v item) error {
+		got = append(got, v)
+		return nil
+	}, seqs...); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Merge result is not sorted stably")
+	}
+
+	errInput := errors.New("input")
+	failing := func(yield func(item, error) bool) {
+		yield(item{}, nil)
+		yield(item{}, errInput)
+	}
+	if err := Merge(compareItems, func(item) error { return nil }, seqs[0], failing); err != errInput {
+		t.Errorf("Merge returned %v, expected %v", err, errInput)
+	}
+}
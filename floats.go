@@ -0,0 +1,251 @@
+package sort
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+)
+
+// less reports whether a is less than b, ordering NaNs before all other values just like cmp.Less.
+// All algorithms for ordered types use it, so they produce the same result as slices.Sort for floating point values containing NaNs.
+// For integers and strings the NaN check is optimized away by the compiler.
+func less[T cmp.Ordered](a, b T) bool {
+	return (isNaN(a) && !isNaN(b)) || a < b
+}
+
+// isNaN reports whether x is a NaN without requiring it to be a floating point type.
+func isNaN[T cmp.Ordered](x T) bool {
+	return x != x
+}
+
+// FloatOrder defines how floating point values are ordered with regard to NaNs and signed zeros.
+// The sorting functions for ordered types always use NaNFirst, the functions with the suffix Floats accept any order.
+type FloatOrder int
+
+const (
+	// NaNFirst orders all NaNs before all other values and considers negative and positive zero equal.
+	// This is the order used by cmp.Compare, slices.Sort and all sorting functions for ordered types in this package.
+	NaNFirst FloatOrder = iota
+	// NaNLast orders all NaNs after all other values and considers negative and positive zero equal.
+	NaNLast
+	// TotalOrder implements the totalOrder predicate of IEEE 754, ordering negative zero before positive zero and NaNs by their sign and payload.
+	// Negative NaNs are ordered before negative infinity and positive NaNs after positive infinity.
+	TotalOrder
+)
+
+// CompareFloats returns a comparison function implementing the given order, which can be used with the sorting functions accepting a comparison function.
+func CompareFloats[T float32 | float64](order FloatOrder) func(a, b T) int {
+	switch order {
+	case NaNLast:
+		return func(a, b T) int {
+			switch nanA, nanB := isNaN(a), isNaN(b); {
+			case nanA && nanB:
+				return 0
+			case nanA:
+				return 1
+			case nanB:
+				return -1
+			}
+			return cmp.Compare(a, b)
+		}
+	case TotalOrder:
+		return func(a, b T) int { return cmp.Compare(floatKey(a, TotalOrder), floatKey(b, TotalOrder)) }
+	}
+	return cmp.Compare[T]
+}
+
+// RadixSortFloats sorts floating point values in the given order using radix sort.
+// Every value is mapped to an unsigned integer key with the same order, which is then sorted along with the values.
+// The sort is stable, which is only visible for values that are considered equal but are distinguishable, such as negative and positive zero or NaNs with different payloads.
+func RadixSortFloats[T float32 | float64](items []T, order FloatOrder) []T {
+	if len(items) < 2 {
+		return items
+	}
+	keys := make([]uint64, len(items))
+	for i, v := range items {
+		keys[i] = floatKey(v, order)
+	}
+	radixSortUintPairs(keys, items, nil, nil)
+	return items
+}
+
+// RadixSortFloatsDesc is the equivalent of RadixSortFloats that sorts in the reverse of the given order.
+// Like RadixSortDesc, it inverts the keys instead of reversing the result, which keeps values that are considered equal in their original order.
+func RadixSortFloatsDesc[T float32 | float64](items []T, order FloatOrder) []T {
+	if len(items) < 2 {
+		return items
+	}
+	keys := make([]uint64, len(items))
+	for i, v := range items {
+		keys[i] = ^floatKey(v, order)
+	}
+	radixSortUintPairs(keys, items, nil, nil)
+	return items
+}
+
+// RadixSortFloatsCtx is the equivalent of RadixSortCtx for floating point values in the given order.
+// Cancellation is checked and progress is reported before every pass over the keys, just like RadixSortCtx does.
+func RadixSortFloatsCtx[T float32 | float64](ctx context.Context, items []T, order FloatOrder) error {
+	if len(items) < 2 {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	keys := make([]uint64, len(items))
+	for i, v := range items {
+		keys[i] = floatKey(v, order)
+	}
+	p := newProgress(ctx, 8)
+	if !radixSortUintPairs(keys, items, ctx.Done(), p) {
+		return ctx.Err()
+	}
+	p.Finish()
+	return nil
+}
+
+// InsertionSortFloats is the equivalent of InsertionSort for floating point values in the given order.
+func InsertionSortFloats[T float32 | float64](items []T, order FloatOrder) []T {
+	return InsertionSortFunc(items, CompareFloats[T](order))
+}
+
+// InsertSortedFloats is the equivalent of InsertSorted for floating point values, which have to be sorted in the given order.
+func InsertSortedFloats[T float32 | float64](sorted []T, insert T, order FloatOrder) []T {
+	compare := CompareFloats[T](order)
+	out := append(sorted, insert)
+	for position := len(sorted); position > 0 && compare(out[position], out[position-1]) < 0; position-- {
+		out[position], out[position-1] = out[position-1], out[position]
+	}
+	return out
+}
+
+// QuickSortFloats is the equivalent of QuickSort for floating point values in the given order.
+func QuickSortFloats[T float32 | float64](items []T, order FloatOrder) []T {
+	return QuickSortFunc(items, CompareFloats[T](order))
+}
+
+// MergeSortFloats is the equivalent of MergeSort for floating point values in the given order.
+func MergeSortFloats[T float32 | float64](items []T, order FloatOrder) []T {
+	return MergeSortFunc(items, CompareFloats[T](order))
+}
+
+// MergeSortFloatsCtx is the equivalent of MergeSortCtx for floating point values in the given order.
+func MergeSortFloatsCtx[T float32 | float64](ctx context.Context, items []T, order FloatOrder) error {
+	if len(items) < 2 {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	tmp := make([]T, len(items))
+	copy(tmp, items)
+
+	p := newProgress(ctx, mergeWork(len(items)))
+	if !mergeSortFuncCtx(tmp, items, CompareFloats[T](order), ctx.Done(), p) {
+		return ctx.Err()
+	}
+	p.Finish()
+	return nil
+}
+
+// MergeSortFloatsDesc is the equivalent of MergeSortDesc for floating point values, sorting in the reverse of the given order.
+func MergeSortFloatsDesc[T float32 | float64](items []T, order FloatOrder) []T {
+	return MergeSortFunc(items, compareFloatsDesc[T](order))
+}
+
+// MergeSortedSetsFloats is the equivalent of MergeSortedSets for floating point values, which have to be sorted in the given order.
+func MergeSortedSetsFloats[T float32 | float64](a, b []T, order FloatOrder) []T {
+	return MergeSortedSetsFunc(a, b, CompareFloats[T](order))
+}
+
+// SortFloats is the equivalent of Sort for floating point values in the given order.
+// Small slices are sorted using insertion sort, large slices using RadixSortFloats and everything else using slices.SortFunc.
+func SortFloats[T float32 | float64](items []T, order FloatOrder) []T {
+	t := CurrentThresholds()
+	switch {
+	case len(items) <= t.Insertion:
+		return InsertionSortFloats(items, order)
+	case len(items) >= t.Radix:
+		return RadixSortFloats(items, order)
+	}
+	slices.SortFunc(items, CompareFloats[T](order))
+	return items
+}
+
+// SortStableFloats is the equivalent of SortStable for floating point values in the given order.
+// Small slices are sorted using insertion sort, large slices using RadixSortFloats and everything else using merge sort.
+func SortStableFloats[T float32 | float64](items []T, order FloatOrder) []T {
+	t := CurrentThresholds()
+	switch {
+	case len(items) <= t.Insertion:
+		return InsertionSortFloats(items, order)
+	case len(items) >= t.RadixStable:
+		return RadixSortFloats(items, order)
+	}
+	return MergeSortFloats(items, order)
+}
+
+// SortFloatsDesc is the equivalent of SortFloats that sorts in the reverse of the given order.
+// Small slices are sorted using insertion sort, large slices using RadixSortFloatsDesc and everything else using slices.SortFunc.
+func SortFloatsDesc[T float32 | float64](items []T, order FloatOrder) []T {
+	t := CurrentThresholds()
+	switch {
+	case len(items) <= t.Insertion:
+		return InsertionSortFunc(items, compareFloatsDesc[T](order))
+	case len(items) >= t.Radix:
+		return RadixSortFloatsDesc(items, order)
+	}
+	slices.SortFunc(items, compareFloatsDesc[T](order))
+	return items
+}
+
+// SortStableFloatsDesc is the equivalent of SortStableFloats that sorts in the reverse of the given order.
+// Small slices are sorted using insertion sort, large slices using RadixSortFloatsDesc and everything else using MergeSortFloatsDesc.
+func SortStableFloatsDesc[T float32 | float64](items []T, order FloatOrder) []T {
+	t := CurrentThresholds()
+	switch {
+	case len(items) <= t.Insertion:
+		return InsertionSortFunc(items, compareFloatsDesc[T](order))
+	case len(items) >= t.RadixStable:
+		return RadixSortFloatsDesc(items, order)
+	}
+	return MergeSortFloatsDesc(items, order)
+}
+
+// compareFloatsDesc returns a comparison function for the reverse of the given order.
+func compareFloatsDesc[T float32 | float64](order FloatOrder) func(a, b T) int {
+	compare := CompareFloats[T](order)
+	return func(a, b T) int { return compare(b, a) }
+}
+
+// floatKey maps a floating point value to an unsigned integer with the same position in the given order.
+// Negative values have all bits inverted to reverse their order while positive values only have their sign bit flipped.
+// The results are never 0 or math.MaxUint64 for values other than NaNs, leaving those for NaNFirst and NaNLast.
+func floatKey[T float32 | float64](v T, order FloatOrder) uint64 {
+	if order != TotalOrder {
+		switch {
+		case isNaN(v) && order == NaNFirst:
+			return 0
+		case isNaN(v):
+			return math.MaxUint64
+		case v == 0:
+			// Normalize negative zero
+			v = 0
+		}
+	}
+	// Values of type float32 are not converted to float64 since that could change the payload of NaNs
+	if f, ok := any(v).(float32); ok {
+		bits := math.Float32bits(f)
+		if bits&(1<<31) != 0 {
+			return uint64(^bits)
+		}
+		return uint64(bits ^ 1<<31)
+	}
+	bits := math.Float64bits(float64(v))
+	if bits&(1<<63) != 0 {
+		return ^bits
+	}
+	return bits ^ 1<<63
+}
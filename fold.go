@@ -0,0 +1,133 @@
+package sort
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fossoreslp/go-sort/keyenc"
+)
+
+// EnglishArticles contains the English articles, intended to be used as FoldOptions.Articles.
+var EnglishArticles = []string{"a", "an", "the"}
+
+// FoldOptions configures case-insensitive comparison and sorting of strings.
+// The zero value compares strings using only Unicode simple case folding.
+type FoldOptions struct {
+	// Articles are ignored at the start of a string if they are followed by a space, matched case-insensitively.
+	// Only a single article is removed, so "The A-Team" is ordered as "A-Team".
+	Articles []string
+	// IgnorePunctuation ignores punctuation and white space at the start of a string.
+	IgnorePunctuation bool
+}
+
+// FoldCompare compares two strings case-insensitively using Unicode simple case folding.
+// Strings that are equal after folding are ordered by their bytes, so the result is only 0 if both strings are identical.
+func FoldCompare(a, b string) int {
+	return FoldOptions{}.Compare(a, b)
+}
+
+// FoldSort sorts strings case-insensitively as defined by FoldCompare.
+// The folded form of every string is computed only once and the results are sorted using radix sort.
+func FoldSort(items []string) []string {
+	return FoldOptions{}.Sort(items)
+}
+
+// Compare compares two strings case-insensitively after removing the leading parts ignored by the options.
+// Strings that are equal after this are ordered case-insensitively including the ignored parts and then by their bytes, so the result is only 0 if both strings are identical.
+func (o FoldOptions) Compare(a, b string) int {
+	if c := foldCompare(o.trim(a), o.trim(b)); c != 0 {
+		return c
+	}
+	if c := foldCompare(a, b); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// Sort sorts strings in the order defined by Compare.
+// A key consisting of the folded forms and the original string is computed only once for every string and the keys are sorted using radix sort.
+func (o FoldOptions) Sort(items []string) []string {
+	keys := make([]string, len(items))
+	var buf, folded []byte
+	for i, s := range items {
+		folded = appendFolded(folded[:0], o.trim(s))
+		buf = keyenc.AppendBytes(buf[:0], folded, keyenc.Asc)
+		if o.trims() {
+			folded = appendFolded(folded[:0], s)
+			buf = keyenc.AppendBytes(buf, folded, keyenc.Asc)
+		}
+		keys[i] = string(append(buf, s...))
+	}
+	radixSortStringPairs(keys, items)
+	return items
+}
+
+// trims reports whether the options cause any part of a string to be ignored.
+func (o FoldOptions) trims() bool {
+	return o.IgnorePunctuation || len(o.Articles) > 0
+}
+
+// trim removes leading punctuation and articles according to the options.
+func (o FoldOptions) trim(s string) string {
+	if o.IgnorePunctuation {
+		s = strings.TrimLeftFunc(s, func(r rune) bool { return unicode.IsPunct(r) || unicode.IsSpace(r) })
+	}
+	for _, article := range o.Articles {
+		if len(s) > len(article) && s[len(article)] == ' ' && strings.EqualFold(s[:len(article)], article) {
+			return strings.TrimLeft(s[len(article):], " ")
+		}
+	}
+	return s
+}
+
+// foldCompare compares two strings rune by rune after case folding.
+func foldCompare(a, b string) int {
+	for a != "" && b != "" {
+		ra, sizeA := utf8.DecodeRuneInString(a)
+		rb, sizeB := utf8.DecodeRuneInString(b)
+		if fa, fb := foldRune(ra), foldRune(rb); fa != fb {
+			if fa < fb {
+				return -1
+			}
+			return 1
+		}
+		a, b = a[sizeA:], b[sizeB:]
+	}
+	switch {
+	case a != "":
+		return 1
+	case b != "":
+		return -1
+	}
+	return 0
+}
+
+// appendFolded appends the case folded form of s to dst.
+// Since UTF-8 preserves the order of code points, comparing the results byte by byte is equivalent to foldCompare.
+func appendFolded(dst []byte, s string) []byte {
+	for _, r := range s {
+		dst = utf8.AppendRune(dst, foldRune(r))
+	}
+	return dst
+}
+
+// foldRune maps a rune to a canonical representative of all runes that are equivalent under simple case folding.
+// This is the smallest lower case rune among them or the smallest rune if none of them are lower case.
+func foldRune(r rune) rune {
+	// Fast path for ASCII
+	if r < utf8.RuneSelf {
+		if 'A' <= r && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return r
+	}
+	best := r
+	bestLower := unicode.IsLower(r)
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if lower := unicode.IsLower(f); lower && !bestLower || lower == bestLower && f < best {
+			best, bestLower = f, lower
+		}
+	}
+	return best
+}
@@ -7,19 +7,49 @@ import "cmp"
 // For larger, unsorted values its complexity is O(n²), leading to very poor performance.
 func InsertionSort[T cmp.Ordered](items []T) []T {
 	for i := range items {
-		for position := i; position > 0 && items[position-1] > items[position]; position-- {
+		for position := i; position > 0 && less(items[position], items[position-1]); position-- {
 			items[position], items[position-1] = items[position-1], items[position]
 		}
 	}
 	return items
 }
 
+// insertionSortLimit sorts items using insertion sort unless this requires more than limit moves, reporting whether the items are sorted.
+// If it gives up, the items are left partially sorted, but items with the same value are still in their original order.
+func insertionSortLimit[T cmp.Ordered](items []T, limit int) bool {
+	for i := range items {
+		position := i
+		for position > 0 && less(items[i], items[position-1]) {
+			position--
+		}
+		if limit -= i - position; limit < 0 {
+			return false
+		}
+		// Moving the item only once all smaller items are known avoids repeated swaps
+		item := items[i]
+		copy(items[position+1:i+1], items[position:i])
+		items[position] = item
+	}
+	return true
+}
+
 // InsertSorted inserts a single element into an already sorted slice.
 // It is more efficient at this operation than resorting the entire array.
 func InsertSorted[T cmp.Ordered](sorted []T, insert T) []T {
 	out := append(sorted, insert)
-	for position := len(sorted); position > 0 && out[position-1] > out[position]; position-- {
+	for position := len(sorted); position > 0 && less(out[position], out[position-1]); position-- {
 		out[position], out[position-1] = out[position-1], out[position]
 	}
 	return out
 }
+
+// InsertionSortFunc is the equivalent of InsertionSort for any type of data, using the comparison function cmp to determine the order.
+// Like InsertionSort, it is stable and only good for very small slices or almost sorted data.
+func InsertionSortFunc[T any](items []T, cmp func(a, b T) int) []T {
+	for i := range items {
+		for position := i; position > 0 && cmp(items[position-1], items[position]) > 0; position-- {
+			items[position], items[position-1] = items[position-1], items[position]
+		}
+	}
+	return items
+}
@@ -0,0 +1,123 @@
+package sort
+
+import "cmp"
+
+// Uint128 is an unsigned 128-bit integer consisting of its high and low 64 bits.
+// Other structs with the same fields can be converted to it directly.
+type Uint128 struct {
+	Hi, Lo uint64
+}
+
+// Int128 is a signed 128-bit integer in two's complement consisting of its high and low 64 bits.
+// Like for other signed integers, the sign is stored in the most significant bit of Hi.
+type Int128 struct {
+	Hi int64
+	Lo uint64
+}
+
+// Integer128 is a constraint for the 128-bit integer types.
+type Integer128 interface {
+	Uint128 | Int128
+}
+
+// Uint128From64 returns v as a Uint128.
+func Uint128From64(v uint64) Uint128 {
+	return Uint128{Lo: v}
+}
+
+// Int128From64 returns v as an Int128, extending its sign into the high bits.
+func Int128From64(v int64) Int128 {
+	return Int128{Hi: v >> 63, Lo: uint64(v)}
+}
+
+// Compare returns -1 if a is less than b, 0 if they are equal and +1 if a is greater than b.
+func (a Uint128) Compare(b Uint128) int {
+	if c := cmp.Compare(a.Hi, b.Hi); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Lo, b.Lo)
+}
+
+// Compare returns -1 if a is less than b, 0 if they are equal and +1 if a is greater than b.
+func (a Int128) Compare(b Int128) int {
+	if c := cmp.Compare(a.Hi, b.Hi); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Lo, b.Lo)
+}
+
+// Compare128 compares two 128-bit integers and can be used with the sorting functions accepting a comparison function.
+func Compare128[T Integer128](a, b T) int {
+	return uint128Key(a).Compare(uint128Key(b))
+}
+
+// RadixSort128 sorts 128-bit integers using radix sort, performing a pass over the low and then the high 64 bits.
+func RadixSort128[T Integer128](items []T) []T {
+	if len(items) < 2 {
+		return items
+	}
+	keys := make([]Uint128, len(items))
+	for i, v := range items {
+		keys[i] = uint128Key(v)
+	}
+	radixSortUint128Pairs(keys, items)
+	return items
+}
+
+// RadixSortBy128 sorts items by a 128-bit integer key using radix sort.
+// The key is retrieved only once per item.
+// The sort is stable, therefore items with equal keys retain their original order.
+func RadixSortBy128[T any, K Integer128](items []T, key func(T) K) []T {
+	if len(items) < 2 {
+		return items
+	}
+	keys := make([]Uint128, len(items))
+	for i, v := range items {
+		keys[i] = uint128Key(key(v))
+	}
+	radixSortUint128Pairs(keys, items)
+	return items
+}
+
+// MergeSort128 sorts 128-bit integers using merge sort.
+// It is stable, but this is not observable for integers.
+func MergeSort128[T Integer128](items []T) []T {
+	return MergeSortFunc(items, Compare128[T])
+}
+
+// uint128Key maps a 128-bit integer to an unsigned one with the same order.
+// Signed integers have the sign bit of their high half flipped, so that comparing both halves as unsigned integers orders negative values first.
+func uint128Key[T Integer128](v T) Uint128 {
+	switch v := any(v).(type) {
+	case Int128:
+		return Uint128{Hi: uint64(v.Hi) ^ 1<<63, Lo: v.Lo}
+	case Uint128:
+		return v
+	}
+	panic("unreachable")
+}
+
+// radixSortUint128Pairs sorts keys and vals by the keys using radixSortUintPairs for the low and then the high 64 bits.
+// Since both passes are stable, the second one keeps the order established by the first one for keys with equal high bits.
+// Like SortByKeys, it sorts a permutation instead of moving the keys and values in every pass.
+func radixSortUint128Pairs[V any](keys []Uint128, vals []V) {
+	perm := make([]int, len(keys))
+	for i := range perm {
+		perm[i] = i
+	}
+	digits := make([]uint64, len(keys))
+	for i, k := range keys {
+		digits[i] = k.Lo
+	}
+	radixSortUintPairs(digits, perm, nil, nil)
+	for i, p := range perm {
+		digits[i] = keys[p].Hi
+	}
+	radixSortUintPairs(digits, perm, nil, nil)
+
+	sorted := make([]V, len(vals))
+	for i, p := range perm {
+		sorted[i] = vals[p]
+	}
+	copy(vals, sorted)
+}
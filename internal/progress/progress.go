@@ -0,0 +1,49 @@
+// Package progress implements the rate limited progress reporting shared by the sorting functions and the extsort package.
+package progress
+
+// Progress tracks the work done by a single operation and reports the completed fraction to a callback.
+// Reports are limited to steps of at least one percent, so the callback is called at most about 100 times.
+// The total can be changed while reporting for work that does not know its total amount in advance, in which case only increasing fractions are reported.
+// All methods can be called on a nil Progress, which is used when no callback is registered so that no overhead is added.
+type Progress struct {
+	report func(float64)
+	total  float64
+	done   float64
+	last   float64
+}
+
+// New returns a Progress reporting to fn for the given total amount of work, or nil if fn is nil.
+func New(fn func(fraction float64), total float64) *Progress {
+	if fn == nil {
+		return nil
+	}
+	return &Progress{report: fn, total: total}
+}
+
+// Add records completed work and reports the new fraction if it has increased by at least one percent since the last report.
+func (p *Progress) Add(work float64) {
+	if p == nil {
+		return
+	}
+	p.done += work
+	if fraction := p.done / p.total; fraction >= p.last+0.01 && fraction < 1 {
+		p.last = fraction
+		p.report(fraction)
+	}
+}
+
+// SetTotal changes the total amount of work, e.g. once it is known more precisely.
+func (p *Progress) SetTotal(total float64) {
+	if p != nil {
+		p.total = total
+	}
+}
+
+// Finish reports completion, which is always reported exactly once regardless of rounding errors in the accumulated work.
+func (p *Progress) Finish() {
+	if p == nil {
+		return
+	}
+	p.last = 1
+	p.report(1)
+}
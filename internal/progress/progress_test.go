@@ -0,0 +1,38 @@
+package progress
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	var reports []float64
+	p := New(func(fraction float64) { reports = append(reports, fraction) }, 1000)
+	for range 1000 {
+		p.Add(1)
+	}
+	p.SetTotal(2000)
+	p.Add(500)
+	p.Finish()
+	if len(reports) < 2 || len(reports) > 101 || reports[len(reports)-1] != 1 {
+		t.Fatalf("Progress reported %d times ending with %v", len(reports), reports[len(reports)-1])
+	}
+	if !slices.IsSorted(reports) {
+		t.Errorf("Progress reported decreasing fractions %v", reports)
+	}
+	for i := 1; i < len(reports)-1; i++ {
+		if reports[i]-reports[i-1] < 0.01-1e-9 {
+			t.Errorf("Progress reported %v after %v, which is less than one percent more", reports[i], reports[i-1])
+		}
+	}
+}
+
+func TestProgressNil(t *testing.T) {
+	p := New(nil, 100)
+	if p != nil {
+		t.Fatal("New with nil callback returned a non-nil Progress")
+	}
+	p.Add(1)
+	p.SetTotal(10)
+	p.Finish()
+}
@@ -0,0 +1,61 @@
+package sort
+
+import (
+	"cmp"
+	"iter"
+	"maps"
+	"slices"
+)
+
+// Sorted collects the values from seq into a new slice and sorts it using Sort, which picks the best algorithm for the data.
+func Sorted[T cmp.Ordered](seq iter.Seq[T]) []T {
+	return Sort(slices.Collect(seq))
+}
+
+// SortedStable collects the values from seq into a new slice and sorts it using SortStable.
+func SortedStable[T cmp.Ordered](seq iter.Seq[T]) []T {
+	return SortStable(slices.Collect(seq))
+}
+
+// SortedFunc collects the values from seq into a new slice and sorts it using SortFunc with the comparison function cmp.
+func SortedFunc[T any](seq iter.Seq[T], cmp func(a, b T) int) []T {
+	return SortFunc(slices.Collect(seq), cmp)
+}
+
+// SortedStableFunc collects the values from seq into a new slice and sorts it using SortStableFunc with the comparison function cmp.
+func SortedStableFunc[T any](seq iter.Seq[T], cmp func(a, b T) int) []T {
+	return SortStableFunc(slices.Collect(seq), cmp)
+}
+
+// AppendSorted appends the values from seq to dst, sorts the appended values using Sort and returns the extended slice.
+// If the number of values is known in advance, allocating dst with enough capacity avoids growing it while collecting.
+func AppendSorted[T cmp.Ordered](dst []T, seq iter.Seq[T]) []T {
+	n := len(dst)
+	dst = slices.AppendSeq(dst, seq)
+	Sort(dst[n:])
+	return dst
+}
+
+// SortedSeq returns an iterator over the values from seq in sorted order.
+// The values are only collected and sorted when iteration starts, and again for every further iteration.
+func SortedSeq[T cmp.Ordered](seq iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range Sorted(seq) {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// SortedKeys returns an iterator over the keys of m in sorted order.
+// The keys are collected into a slice of the size of the map and sorted using Sort when iteration starts.
+func SortedKeys[K cmp.Ordered, V any](m map[K]V) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for _, k := range AppendSorted(make([]K, 0, len(m)), maps.Keys(m)) {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,372 @@
+// Package jsonl sorts JSON Lines files by the values found at paths within every line, e.g. logs by ".request.ts".
+//
+// Every line is parsed only once, converting the values of its keys into a byte string that compares like the values using keyenc.
+// The lines themselves are never re-encoded, so the output consists of exactly the same bytes as the input, only in a different order.
+// All sorts are stable, so lines with equal keys retain their original order.
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/fossoreslp/go-sort"
+	"github.com/fossoreslp/go-sort/extsort"
+	"github.com/fossoreslp/go-sort/keyenc"
+)
+
+// Path selects a value within a JSON document. The zero value selects the whole document.
+type Path struct {
+	text     string
+	segments []segment
+}
+
+// segment is either the name of a member of an object or, if index is not negative, an element of an array.
+type segment struct {
+	name  string
+	index int
+}
+
+// ParsePath parses a path consisting of object members and array elements, e.g. ".request.ts" or `.items[0]["content-type"]`.
+// Members are given as .name or ["name"] with name being a JSON string, and array elements as [index] with the first element having index 0.
+// The path "." selects the whole document.
+func ParsePath(s string) (Path, error) {
+	invalid := func(reason string) (Path, error) {
+		return Path{}, errors.New("jsonl: invalid path " + strconv.Quote(s) + ": " + reason)
+	}
+	p := Path{text: s}
+	if s == "." {
+		return p, nil
+	}
+	for rest := s; rest != ""; {
+		switch rest[0] {
+		case '.':
+			// A dot may precede brackets as in jq, e.g. .[0]
+			if len(rest) > 1 && rest[1] == '[' {
+				rest = rest[1:]
+				continue
+			}
+			end := strings.IndexAny(rest[1:], ".[") + 1
+			if end == 0 {
+				end = len(rest)
+			}
+			if end == 1 {
+				return invalid("empty member name")
+			}
+			p.segments = append(p.segments, segment{name: rest[1:end], index: -1})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if len(rest) > 1 && rest[1] == '"' {
+				// The name may contain brackets itself, so the end is found by decoding it
+				d := json.NewDecoder(strings.NewReader(rest[1:]))
+				var name string
+				if err := d.Decode(&name); err != nil {
+					return invalid("invalid member name")
+				}
+				end = 1 + int(d.InputOffset())
+				if end >= len(rest) || rest[end] != ']' {
+					return invalid("missing ]")
+				}
+				p.segments = append(p.segments, segment{name: name, index: -1})
+			} else {
+				if end < 0 {
+					return invalid("missing ]")
+				}
+				i, err := strconv.Atoi(rest[1:end])
+				if err != nil || i < 0 {
+					return invalid("invalid index " + strconv.Quote(rest[1:end]))
+				}
+				p.segments = append(p.segments, segment{index: i})
+			}
+			rest = rest[end+1:]
+		default:
+			return invalid("expected . or [")
+		}
+	}
+	return p, nil
+}
+
+// String returns the path as given to ParsePath.
+func (p Path) String() string {
+	if p.text == "" && len(p.segments) == 0 {
+		return "."
+	}
+	return p.text
+}
+
+// Missing determines how lines are ordered if the path of a key does not exist within them.
+type Missing int
+
+const (
+	// MissingLast orders lines without the key after all other lines, regardless of the direction.
+	MissingLast Missing = iota
+	// MissingFirst orders lines without the key before all other lines, regardless of the direction.
+	MissingFirst
+	// MissingError fails the sort if any line does not have the key.
+	MissingError
+)
+
+// Key describes a value by which lines are sorted.
+// Values of different JSON types are ordered null, false, true, numbers, strings and finally arrays and objects.
+// Numbers are compared by their exact decimal values, so numbers beyond the precision or range of a float64 keep their order.
+// Arrays and objects are compared by their JSON encoding with the members of objects sorted by their names.
+type Key struct {
+	// Path selects the value within every line.
+	Path Path
+	// Descending sorts in descending order of the values.
+	Descending bool
+	// Missing determines the order of lines in which the path does not exist.
+	Missing Missing
+}
+
+// Line is a line of input along with its encoded keys.
+type Line struct {
+	// Text contains the bytes of the line without the terminating newline.
+	Text []byte
+	key  string
+}
+
+// SortLines sorts lines of JSON by the keys in-place.
+// Lines are compared by the first key and only by the following ones if the previous keys are equal.
+// Blank lines are treated as if none of the keys exist in them.
+// The sort is stable, therefore lines for which all keys are equal retain their original order.
+// If any line is not valid JSON or misses a key with the MissingError policy, the lines are left untouched and an error is returned.
+func SortLines(lines [][]byte, keys ...Key) error {
+	encoded := make([]string, len(lines))
+	for i, l := range lines {
+		k, err := appendKey(nil, l, keys)
+		if err != nil {
+			return fmt.Errorf("jsonl: line %d: %w", i+1, err)
+		}
+		encoded[i] = string(k)
+	}
+	sort.SortPairs(encoded, lines)
+	return nil
+}
+
+// SortStream reads lines of JSON from src until EOF, sorts them by the keys and writes them to dst, terminating every line with a newline.
+// If the lines do not fit into the memory limit given by opts, sorted parts are written to temporary files and merged afterwards.
+// Keys are written to the temporary files along with the lines, so lines are never parsed again.
+// The Size option is set by SortStream and must not be set by the caller.
+func SortStream(dst io.Writer, src io.Reader, keys []Key, opts extsort.Options[Line]) error {
+	opts.Size = func(l Line) int { return len(l.Text) + len(l.key) + 48 }
+	s := extsort.New(compareLines, codec{}, opts)
+	defer s.Close()
+
+	r := bufio.NewReader(src)
+	for number := 1; ; number++ {
+		text, err := r.ReadBytes('\n')
+		if len(text) == 0 && err == io.EOF {
+			break
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		text = bytes.TrimSuffix(text, []byte("\n"))
+		key, err := appendKey(nil, text, keys)
+		if err != nil {
+			return fmt.Errorf("jsonl: line %d: %w", number, err)
+		}
+		if err := s.Add(Line{Text: text, key: string(key)}); err != nil {
+			return err
+		}
+	}
+
+	w := bufio.NewWriter(dst)
+	if err := s.Each(func(l Line) error {
+		w.Write(l.Text)
+		return w.WriteByte('\n')
+	}); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// compareLines compares lines by their encoded keys.
+func compareLines(a, b Line) int {
+	return strings.Compare(a.key, b.key)
+}
+
+// Missing keys are encoded as a single byte before or after those of present values, which is never inverted so they keep their position in descending order.
+const (
+	tagMissingFirst = 0x00
+	tagPresent      = 0x01
+	tagMissingLast  = 0x02
+)
+
+// Present values start with a byte determining the order of JSON types.
+const (
+	kindNull = iota
+	kindFalse
+	kindTrue
+	kindNumber
+	kindString
+	kindComposite
+)
+
+// appendKey parses line and appends the encoding of all keys to dst.
+func appendKey(dst []byte, line []byte, keys []Key) ([]byte, error) {
+	var doc any
+	blank := len(bytes.TrimSpace(line)) == 0
+	if !blank {
+		d := json.NewDecoder(bytes.NewReader(line))
+		d.UseNumber()
+		if err := d.Decode(&doc); err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(line[d.InputOffset():])) != 0 {
+			return nil, errors.New("invalid data after JSON value")
+		}
+	}
+	for _, k := range keys {
+		v, ok := k.Path.lookup(doc)
+		if !ok || blank {
+			switch k.Missing {
+			case MissingFirst:
+				dst = append(dst, tagMissingFirst)
+			case MissingLast:
+				dst = append(dst, tagMissingLast)
+			default:
+				return nil, errors.New("missing key " + k.Path.String())
+			}
+			continue
+		}
+		o := keyenc.Asc
+		if k.Descending {
+			o = keyenc.Desc
+		}
+		dst = appendValue(append(dst, tagPresent), v, o)
+	}
+	return dst, nil
+}
+
+// lookup returns the value at the path within doc, which has been decoded using json.Decoder.UseNumber.
+func (p Path) lookup(doc any) (any, bool) {
+	for _, s := range p.segments {
+		if s.index >= 0 {
+			a, ok := doc.([]any)
+			if !ok || s.index >= len(a) {
+				return nil, false
+			}
+			doc = a[s.index]
+			continue
+		}
+		o, ok := doc.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if doc, ok = o[s.name]; !ok {
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+// appendValue appends the encoding of a JSON value to dst, starting with its kind.
+func appendValue(dst []byte, v any, o keyenc.Order) []byte {
+	kind := func(k byte) []byte {
+		if o == keyenc.Desc {
+			k = ^k
+		}
+		return append(dst, k)
+	}
+	switch v := v.(type) {
+	case nil:
+		return kind(kindNull)
+	case bool:
+		if v {
+			return kind(kindTrue)
+		}
+		return kind(kindFalse)
+	case json.Number:
+		return appendNumber(kind(kindNumber), string(v), o)
+	case string:
+		return keyenc.AppendString(kind(kindString), v, o)
+	}
+	// Objects are encoded with their members sorted by name, so the result does not depend on their original order
+	b, _ := json.Marshal(v)
+	return keyenc.AppendBytes(kind(kindComposite), b, o)
+}
+
+// appendNumber appends the encoding of a JSON number, which orders numbers by their exact decimal values.
+// The number is normalized to its sign, a decimal exponent and its digits without leading and trailing zeros, e.g. 12.50 to +, 2 and "125" for 0.125×10².
+// For numbers with the same sign and exponent, comparing the digits as strings compares the numbers, while negative numbers need both in the opposite order.
+func appendNumber(dst []byte, s string, o keyenc.Order) []byte {
+	sign := func(b byte) []byte {
+		if o == keyenc.Desc {
+			b = ^b
+		}
+		return append(dst, b)
+	}
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	var exponent int64
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		// Exponents beyond ±2^62 are clamped, leaving enough room for the number of digits to be added
+		e, _ := strconv.ParseInt(strings.TrimPrefix(s[i+1:], "+"), 10, 64)
+		exponent = min(max(e, -1<<62), 1<<62)
+		s = s[:i]
+	}
+	integer, fraction, _ := strings.Cut(s, ".")
+	digits := strings.TrimLeft(integer+fraction, "0")
+	exponent += int64(len(digits) - len(fraction))
+	digits = strings.TrimRight(digits, "0")
+
+	switch {
+	case digits == "":
+		// Negative zero is equal to zero
+		return sign(1)
+	case negative:
+		dst = sign(0)
+		o = !o
+	default:
+		dst = sign(2)
+	}
+	return keyenc.AppendString(keyenc.AppendInt64(dst, exponent, o), digits, o)
+}
+
+// codec writes lines to temporary files, prefixed by the lengths of their key and text.
+type codec struct{}
+
+func (codec) Encode(w *bufio.Writer, l Line) error {
+	w.Write(binary.AppendUvarint(nil, uint64(len(l.key))))
+	w.WriteString(l.key)
+	w.Write(binary.AppendUvarint(nil, uint64(len(l.Text))))
+	_, err := w.Write(l.Text)
+	return err
+}
+
+func (codec) Decode(r *bufio.Reader) (Line, error) {
+	key, err := readBytes(r)
+	if err != nil {
+		return Line{}, err
+	}
+	text, err := readBytes(r)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return Line{Text: text, key: string(key)}, err
+}
+
+// readBytes reads a byte slice prefixed by its length.
+func readBytes(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,196 @@
+package jsonl
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fossoreslp/go-sort/extsort"
+)
+
+func mustParsePath(t *testing.T, s string) Path {
+	t.Helper()
+	p, err := ParsePath(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func sortLines(t *testing.T, input string, keys ...Key) string {
+	t.Helper()
+	lines := bytes.Split([]byte(input), []byte("\n"))
+	if err := SortLines(lines, keys...); err != nil {
+		t.Fatal(err)
+	}
+	return string(bytes.Join(lines, []byte("\n")))
+}
+
+func TestSortLines(t *testing.T) {
+	ts := mustParsePath(t, ".request.ts")
+	tests := []struct {
+		Name  string
+		Keys  []Key
+		Input string
+		Want  string
+	}{
+		{
+			"nested numbers",
+			[]Key{{Path: ts}},
+			`{"request":{"ts":20}}` + "\n" + `{ "request" : { "ts" : 3.5 } }` + "\n" + `{"request":{"ts":-1e3}}`,
+			`{"request":{"ts":-1e3}}` + "\n" + `{ "request" : { "ts" : 3.5 } }` + "\n" + `{"request":{"ts":20}}`,
+		},
+		{
+			"exact integers",
+			[]Key{{Path: ts}},
+			`{"request":{"ts":9007199254740993}}` + "\n" + `{"request":{"ts":9007199254740992.0}}` + "\n" + `{"request":{"ts":9223372036854775807}}` + "\n" + `{"request":{"ts":9007199254740992}}`,
+			`{"request":{"ts":9007199254740992.0}}` + "\n" + `{"request":{"ts":9007199254740992}}` + "\n" + `{"request":{"ts":9007199254740993}}` + "\n" + `{"request":{"ts":9223372036854775807}}`,
+		},
+		{
+			"exact decimals",
+			[]Key{{Path: mustParsePath(t, ".v")}},
+			`{"v":0.10000000000000001}` + "\n" + `{"v":0.1}` + "\n" + `{"v":2e400}` + "\n" + `{"v":1e400}` + "\n" + `{"v":18446744073709551617}` + "\n" + `{"v":18446744073709551616}` + "\n" + `{"v":-1e400}` + "\n" + `{"v":-2e400}`,
+			`{"v":-2e400}` + "\n" + `{"v":-1e400}` + "\n" + `{"v":0.1}` + "\n" + `{"v":0.10000000000000001}` + "\n" + `{"v":18446744073709551616}` + "\n" + `{"v":18446744073709551617}` + "\n" + `{"v":1e400}` + "\n" + `{"v":2e400}`,
+		},
+		{
+			"equal decimals",
+			[]Key{{Path: mustParsePath(t, ".v")}},
+			`{"v":1.50}` + "\n" + `{"v":-0.5}` + "\n" + `{"v":0.15E1}` + "\n" + `{"v":-0.05e1}` + "\n" + `{"v":-0.55}` + "\n" + `{"v":150e-2}` + "\n" + `{"v":1.5}`,
+			`{"v":-0.55}` + "\n" + `{"v":-0.5}` + "\n" + `{"v":-0.05e1}` + "\n" + `{"v":1.50}` + "\n" + `{"v":0.15E1}` + "\n" + `{"v":150e-2}` + "\n" + `{"v":1.5}`,
+		},
+		{
+			"descending decimals",
+			[]Key{{Path: mustParsePath(t, ".v"), Descending: true}},
+			`{"v":-0.15}` + "\n" + `{"v":0.001}` + "\n" + `{"v":-0.1}` + "\n" + `{"v":0}` + "\n" + `{"v":100}` + "\n" + `{"v":0.0011}`,
+			`{"v":100}` + "\n" + `{"v":0.0011}` + "\n" + `{"v":0.001}` + "\n" + `{"v":0}` + "\n" + `{"v":-0.1}` + "\n" + `{"v":-0.15}`,
+		},
+		{
+			"types",
+			[]Key{{Path: mustParsePath(t, ".v")}},
+			`{"v":"a"}` + "\n" + `{"v":[1]}` + "\n" + `{"v":1}` + "\n" + `{"v":true}` + "\n" + `{"v":null}` + "\n" + `{"v":false}` + "\n" + `{"v":-0}` + "\n" + `{"v":0}`,
+			`{"v":null}` + "\n" + `{"v":false}` + "\n" + `{"v":true}` + "\n" + `{"v":-0}` + "\n" + `{"v":0}` + "\n" + `{"v":1}` + "\n" + `{"v":"a"}` + "\n" + `{"v":[1]}`,
+		},
+		{
+			"missing last descending",
+			[]Key{{Path: ts, Descending: true}},
+			`{"request":{}}` + "\n" + `{"request":{"ts":"b"}}` + "\n" + `[]` + "\n" + `{"request":{"ts":"a"}}` + "\n" + ``,
+			`{"request":{"ts":"b"}}` + "\n" + `{"request":{"ts":"a"}}` + "\n" + `{"request":{}}` + "\n" + `[]` + "\n" + ``,
+		},
+		{
+			"missing first",
+			[]Key{{Path: ts, Missing: MissingFirst}},
+			`{"request":{"ts":1}}` + "\n" + `{"request":{"id":1}}`,
+			`{"request":{"id":1}}` + "\n" + `{"request":{"ts":1}}`,
+		},
+		{
+			"array elements and quoted members",
+			[]Key{{Path: mustParsePath(t, `.items[1]["a.b"]`)}},
+			`{"items":[0,{"a.b":"y"}]}` + "\n" + `{"items":[0,{"a.b":"x"}]}`,
+			`{"items":[0,{"a.b":"x"}]}` + "\n" + `{"items":[0,{"a.b":"y"}]}`,
+		},
+		{
+			"objects",
+			[]Key{{}},
+			`{"b":1,"a":2}` + "\n" + `{"a":1, "b":2}`,
+			`{"a":1, "b":2}` + "\n" + `{"b":1,"a":2}`,
+		},
+		{
+			"multiple keys",
+			[]Key{{Path: mustParsePath(t, ".level")}, {Path: ts, Descending: true}},
+			`{"level":"info","request":{"ts":1}}` + "\n" + `{"level":"error","request":{"ts":1}}` + "\n" + `{"level":"info","request":{"ts":2}}`,
+			`{"level":"error","request":{"ts":1}}` + "\n" + `{"level":"info","request":{"ts":2}}` + "\n" + `{"level":"info","request":{"ts":1}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if got := sortLines(t, tt.Input, tt.Keys...); got != tt.Want {
+				t.Errorf("SortLines resulted in\n%s\nexpected\n%s", got, tt.Want)
+			}
+		})
+	}
+
+	invalid := []string{`{"a":1`, `{"a":1} x`, `{"a":1}{"a":2}`}
+	for _, line := range invalid {
+		lines := [][]byte{[]byte(`{"a":2}`), []byte(line)}
+		if err := SortLines(lines, Key{}); err == nil || !strings.HasPrefix(string(lines[1]), line) {
+			t.Errorf("SortLines accepted invalid line %q", line)
+		}
+	}
+	lines := [][]byte{[]byte(`{"a":2}`), []byte(`{"b":1}`)}
+	if err := SortLines(lines, Key{Path: mustParsePath(t, ".a"), Missing: MissingError}); err == nil {
+		t.Error("SortLines accepted a missing key with MissingError")
+	}
+}
+
+func TestSortStream(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	var input strings.Builder
+	var lines [][]byte
+	for i := range 5000 {
+		var line string
+		switch random.IntN(10) {
+		case 0:
+			line = fmt.Sprintf(`{"id":%d}`, i)
+		case 1:
+			line = fmt.Sprintf(`{"id":%d,"request":{"ts":"%d"}}`, i, random.IntN(50))
+		default:
+			line = fmt.Sprintf(`{"id":%d, "request": {"ts": %d.%d}}`, i, random.IntN(50), random.IntN(2))
+		}
+		lines = append(lines, []byte(line))
+		input.WriteString(line + "\n")
+	}
+	keys := []Key{{Path: mustParsePath(t, ".request.ts"), Missing: MissingFirst}}
+	if err := SortLines(lines, keys...); err != nil {
+		t.Fatal(err)
+	}
+	want := string(bytes.Join(lines, []byte("\n"))) + "\n"
+
+	dir := t.TempDir()
+	var out bytes.Buffer
+	if err := SortStream(&out, strings.NewReader(input.String()), keys, extsort.Options[Line]{MemoryLimit: 16 << 10, TempDir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != want {
+		t.Error("SortStream result differs from SortLines")
+	}
+
+	out.Reset()
+	if err := SortStream(&out, strings.NewReader("{\"a\":2}\r\n{\"a\":1}"), []Key{{Path: mustParsePath(t, ".a")}}, extsort.Options[Line]{}); err != nil || out.String() != "{\"a\":1}\n{\"a\":2}\r\n" {
+		t.Errorf("SortStream returned %v with output %q", err, out.String())
+	}
+	if err := SortStream(&out, strings.NewReader("{}\n{\n"), nil, extsort.Options[Line]{}); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("SortStream returned %v for invalid input", err)
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		Input string
+		Want  []segment
+		Valid bool
+	}{
+		{".", nil, true},
+		{".request.ts", []segment{{"request", -1}, {"ts", -1}}, true},
+		{".items[0].id", []segment{{"items", -1}, {"", 0}, {"id", -1}}, true},
+		{".[2]", []segment{{"", 2}}, true},
+		{`["a]b"].c`, []segment{{"a]b", -1}, {"c", -1}}, true},
+		{"", nil, true},
+		{"request", nil, false},
+		{"..a", nil, false},
+		{".a.", nil, false},
+		{".a[", nil, false},
+		{".a[x]", nil, false},
+		{".a[-1]", nil, false},
+		{`.a["b`, nil, false},
+		{`.a["b"`, nil, false},
+	}
+	for _, tt := range tests {
+		got, err := ParsePath(tt.Input)
+		if (err == nil) != tt.Valid || !slices.Equal(got.segments, tt.Want) {
+			t.Errorf("ParsePath(%q) returned %+v, %v", tt.Input, got.segments, err)
+		}
+	}
+}
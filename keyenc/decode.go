@@ -0,0 +1,160 @@
+package keyenc
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+)
+
+var (
+	// ErrShortKey is returned when a key ends before the field being decoded.
+	ErrShortKey = errors.New("keyenc: key too short")
+	// ErrInvalidKey is returned when a field contains bytes that could not have been produced by the encoder.
+	ErrInvalidKey = errors.New("keyenc: invalid key")
+)
+
+// Decoder decodes the fields of a key in the order in which they were appended.
+// The type and order of every field have to be known since they are not part of the encoding.
+type Decoder struct {
+	key []byte
+}
+
+// NewDecoder returns a decoder reading the fields of key.
+func NewDecoder(key []byte) *Decoder {
+	return &Decoder{key: key}
+}
+
+// Len returns the number of bytes that have not been decoded yet.
+func (d *Decoder) Len() int {
+	return len(d.key)
+}
+
+// next removes the next n bytes from the key, returning a copy with all bits inverted if o is Desc.
+func (d *Decoder) next(n int, o Order) ([]byte, error) {
+	if len(d.key) < n {
+		return nil, ErrShortKey
+	}
+	field := d.key[:n]
+	d.key = d.key[n:]
+	if o == Desc {
+		field = invert(append([]byte(nil), field...), 0, Desc)
+	}
+	return field, nil
+}
+
+// Uint64 decodes a field appended using AppendUint64.
+func (d *Decoder) Uint64(o Order) (uint64, error) {
+	field, err := d.next(8, o)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(field), nil
+}
+
+// Int64 decodes a field appended using AppendInt64.
+func (d *Decoder) Int64(o Order) (int64, error) {
+	v, err := d.Uint64(o)
+	if err != nil {
+		return 0, err
+	}
+	return int64(v ^ 1<<63), nil
+}
+
+// Float64 decodes a field appended using AppendFloat64.
+func (d *Decoder) Float64(o Order) (float64, error) {
+	bits, err := d.Uint64(o)
+	if err != nil {
+		return 0, err
+	}
+	if bits&(1<<63) != 0 {
+		return math.Float64frombits(bits ^ 1<<63), nil
+	}
+	return math.Float64frombits(^bits), nil
+}
+
+// Float32 decodes a field appended using AppendFloat32.
+func (d *Decoder) Float32(o Order) (float32, error) {
+	field, err := d.next(4, o)
+	if err != nil {
+		return 0, err
+	}
+	bits := binary.BigEndian.Uint32(field)
+	if bits&(1<<31) != 0 {
+		return math.Float32frombits(bits ^ 1<<31), nil
+	}
+	return math.Float32frombits(^bits), nil
+}
+
+// Bool decodes a field appended using AppendBool.
+func (d *Decoder) Bool(o Order) (bool, error) {
+	field, err := d.next(1, o)
+	if err != nil {
+		return false, err
+	}
+	switch field[0] {
+	case 0:
+		return false, nil
+	case 1:
+		return true, nil
+	}
+	return false, ErrInvalidKey
+}
+
+// Bytes decodes a field appended using AppendBytes or AppendString.
+func (d *Decoder) Bytes(o Order) ([]byte, error) {
+	// In descending order all bytes are inverted, including the escape sequences
+	esc, escaped, term := byte(escapeByte), byte(escapedByte), byte(terminatorByte)
+	if o == Desc {
+		esc, escaped, term = ^esc, ^escaped, ^term
+	}
+	var out []byte
+	for i := 0; i < len(d.key); i++ {
+		c := d.key[i]
+		if c != esc {
+			if o == Desc {
+				c = ^c
+			}
+			out = append(out, c)
+			continue
+		}
+		if i+1 == len(d.key) {
+			return nil, ErrShortKey
+		}
+		switch d.key[i+1] {
+		case escaped:
+			out = append(out, escapeByte)
+			i++
+		case term:
+			d.key = d.key[i+2:]
+			if out == nil {
+				out = []byte{}
+			}
+			return out, nil
+		default:
+			return nil, ErrInvalidKey
+		}
+	}
+	return nil, ErrShortKey
+}
+
+// String decodes a field appended using AppendString or AppendBytes.
+func (d *Decoder) String(o Order) (string, error) {
+	b, err := d.Bytes(o)
+	return string(b), err
+}
+
+// Time decodes a field appended using AppendTime.
+// The result is always in UTC since the location is not part of the encoding.
+func (d *Decoder) Time(o Order) (time.Time, error) {
+	field, err := d.next(12, o)
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec := int64(binary.BigEndian.Uint64(field) ^ 1<<63)
+	nsec := binary.BigEndian.Uint32(field[8:])
+	if nsec >= 1e9 {
+		return time.Time{}, ErrInvalidKey
+	}
+	return time.Unix(sec, int64(nsec)).UTC(), nil
+}
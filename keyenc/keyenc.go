@@ -0,0 +1,126 @@
+// Package keyenc implements an order-preserving encoding of values into byte strings.
+// Encoded keys compare the same way as the values they were created from when using bytes.Compare,
+// which allows sorting records by composite keys using algorithms that only support byte strings, such as radix sort.
+//
+// A composite key is built by appending its fields one after the other.
+// Every field can be encoded in ascending or descending order, the latter being achieved by inverting all bits of the encoded field.
+// Since all encodings are prefix-free, fields never interfere with the comparison of the following ones.
+package keyenc
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// Order selects whether a field is encoded in ascending or descending order.
+type Order bool
+
+const (
+	// Asc encodes a field so that smaller values produce smaller keys.
+	Asc Order = false
+	// Desc encodes a field so that larger values produce smaller keys.
+	Desc Order = true
+)
+
+// Byte strings are terminated by escapeByte followed by terminatorByte while escapeByte itself is followed by escapedByte.
+// This keeps shorter strings before longer strings starting with the same bytes.
+const (
+	escapeByte     = 0x00
+	escapedByte    = 0xFF
+	terminatorByte = 0x01
+)
+
+// AppendUint64 appends the encoding of v to dst, using 8 bytes in big-endian byte order.
+func AppendUint64(dst []byte, v uint64, o Order) []byte {
+	return invert(binary.BigEndian.AppendUint64(dst, v), len(dst), o)
+}
+
+// AppendInt64 appends the encoding of v to dst.
+// The sign bit is flipped so that comparing the big-endian bytes orders negative values before positive ones.
+func AppendInt64(dst []byte, v int64, o Order) []byte {
+	return AppendUint64(dst, uint64(v)^1<<63, o)
+}
+
+// AppendFloat64 appends the encoding of v to dst, ordered according to the totalOrder predicate of IEEE 754.
+// Negative zero is ordered before positive zero and NaNs are ordered by their sign and payload, placing positive NaNs after positive infinity.
+func AppendFloat64(dst []byte, v float64, o Order) []byte {
+	return AppendUint64(dst, Float64Key(v), o)
+}
+
+// AppendFloat32 is the equivalent of AppendFloat64 for float32, using 4 bytes.
+func AppendFloat32(dst []byte, v float32, o Order) []byte {
+	bits := math.Float32bits(v)
+	if bits&(1<<31) != 0 {
+		bits = ^bits
+	} else {
+		bits ^= 1 << 31
+	}
+	return invert(binary.BigEndian.AppendUint32(dst, bits), len(dst), o)
+}
+
+// AppendBool appends the encoding of v to dst, ordering false before true.
+func AppendBool(dst []byte, v bool, o Order) []byte {
+	b := byte(0)
+	if v {
+		b = 1
+	}
+	return invert(append(dst, b), len(dst), o)
+}
+
+// AppendString appends the encoding of s to dst.
+// Zero bytes are escaped and a terminator is appended, so that strings which are a prefix of another string are ordered first.
+func AppendString(dst []byte, s string, o Order) []byte {
+	start := len(dst)
+	for i := 0; i < len(s); i++ {
+		if s[i] == escapeByte {
+			dst = append(dst, escapeByte, escapedByte)
+		} else {
+			dst = append(dst, s[i])
+		}
+	}
+	return invert(append(dst, escapeByte, terminatorByte), start, o)
+}
+
+// AppendBytes is the equivalent of AppendString for byte slices.
+func AppendBytes(dst []byte, b []byte, o Order) []byte {
+	start := len(dst)
+	for _, c := range b {
+		if c == escapeByte {
+			dst = append(dst, escapeByte, escapedByte)
+		} else {
+			dst = append(dst, c)
+		}
+	}
+	return invert(append(dst, escapeByte, terminatorByte), start, o)
+}
+
+// AppendTime appends the encoding of t to dst as seconds and nanoseconds since the Unix epoch, using 12 bytes.
+// This covers the entire range of time.Time, while the location and monotonic clock reading are not encoded.
+func AppendTime(dst []byte, t time.Time, o Order) []byte {
+	start := len(dst)
+	dst = AppendInt64(dst, t.Unix(), Asc)
+	dst = binary.BigEndian.AppendUint32(dst, uint32(t.Nanosecond()))
+	return invert(dst, start, o)
+}
+
+// Float64Key maps v to an unsigned integer ordered according to the totalOrder predicate of IEEE 754, which AppendFloat64 encodes.
+// It can be used to sort floats by integer keys, e.g. using radix sort.
+// Negative values have all bits inverted to reverse their order while positive values only have their sign bit flipped.
+func Float64Key(v float64) uint64 {
+	bits := math.Float64bits(v)
+	if bits&(1<<63) != 0 {
+		return ^bits
+	}
+	return bits ^ 1<<63
+}
+
+// invert inverts all bits of the field starting at start if o is Desc.
+func invert(dst []byte, start int, o Order) []byte {
+	if o == Desc {
+		for i := start; i < len(dst); i++ {
+			dst[i] = ^dst[i]
+		}
+	}
+	return dst
+}
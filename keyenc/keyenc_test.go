@@ -0,0 +1,172 @@
+package keyenc
+
+import (
+	"bytes"
+	"cmp"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type tuple struct {
+	Int    int64
+	String string
+	Float  float64
+	Bool   bool
+	Time   time.Time
+}
+
+func (a tuple) compare(b tuple) int {
+	return cmp.Or(
+		cmp.Compare(a.Int, b.Int),
+		// Descending
+		strings.Compare(b.String, a.String),
+		cmp.Compare(a.Float, b.Float),
+		cmp.Compare(boolInt(a.Bool), boolInt(b.Bool)),
+		// Descending
+		b.Time.Compare(a.Time),
+	)
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (a tuple) key() []byte {
+	key := AppendInt64(nil, a.Int, Asc)
+	key = AppendString(key, a.String, Desc)
+	key = AppendFloat64(key, a.Float, Asc)
+	key = AppendBool(key, a.Bool, Asc)
+	return AppendTime(key, a.Time, Desc)
+}
+
+func randomTuple(r *rand.Rand) tuple {
+	strs := []string{"", "a", "a\x00", "a\x00\x00", "a\x01", "ab", "b", "\x00", "\xff", "\xff\x00"}
+	floats := []float64{math.Inf(-1), -1.5, -0.25, 0, 0.25, 1.5, math.Inf(1), math.MaxFloat64, -math.SmallestNonzeroFloat64}
+	times := []time.Time{time.Unix(0, 0), time.Unix(-1, 999999999), time.Unix(1, 1), time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)}
+	return tuple{
+		Int:    []int64{math.MinInt64, -1, 0, 1, math.MaxInt64}[r.IntN(5)],
+		String: strs[r.IntN(len(strs))],
+		Float:  floats[r.IntN(len(floats))],
+		Bool:   r.IntN(2) == 1,
+		Time:   times[r.IntN(len(times))],
+	}
+}
+
+func TestOrder(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for range 10000 {
+		a, b := randomTuple(r), randomTuple(r)
+		if got, want := bytes.Compare(a.key(), b.key()), a.compare(b); got != want {
+			t.Fatalf("comparing keys of %+v and %+v returned %d, want %d", a, b, got, want)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	for range 1000 {
+		want := randomTuple(r)
+		d := NewDecoder(want.key())
+		var got tuple
+		var err [5]error
+		got.Int, err[0] = d.Int64(Asc)
+		got.String, err[1] = d.String(Desc)
+		got.Float, err[2] = d.Float64(Asc)
+		got.Bool, err[3] = d.Bool(Asc)
+		got.Time, err[4] = d.Time(Desc)
+		for _, e := range err {
+			if e != nil {
+				t.Fatalf("decoding %+v failed: %v", want, e)
+			}
+		}
+		if d.Len() != 0 {
+			t.Errorf("decoding %+v left %d bytes", want, d.Len())
+		}
+		if got.Int != want.Int || got.String != want.String || got.Float != want.Float || got.Bool != want.Bool || !got.Time.Equal(want.Time) {
+			t.Errorf("decoding %+v returned %+v", want, got)
+		}
+	}
+}
+
+func TestFloatTotalOrder(t *testing.T) {
+	values := []float64{math.Float64frombits(0xFFF8000000000001), math.Inf(-1), -1, math.Copysign(0, -1), 0, 1, math.Inf(1), math.NaN()}
+	if !slices.IsSortedFunc(values, func(a, b float64) int { return cmp.Compare(Float64Key(a), Float64Key(b)) }) {
+		t.Errorf("Float64Key does not order %v", values)
+	}
+	for o, order := range []Order{Asc, Desc} {
+		keys := make([][]byte, len(values))
+		for i, v := range values {
+			keys[i] = AppendFloat64(nil, v, order)
+		}
+		if o == 0 && !slices.IsSortedFunc(keys, bytes.Compare) || o == 1 && !slices.IsSortedFunc(keys, func(a, b []byte) int { return bytes.Compare(b, a) }) {
+			t.Errorf("float keys in order %v are not sorted: %x", order, keys)
+		}
+		for i, key := range keys {
+			got, err := NewDecoder(key).Float64(order)
+			if err != nil || math.Float64bits(got) != math.Float64bits(values[i]) {
+				t.Errorf("decoding float %v returned %v, %v", values[i], got, err)
+			}
+		}
+		keys32 := make([][]byte, len(values))
+		for i, v := range values {
+			keys32[i] = AppendFloat32(nil, float32(v), Asc)
+			got, err := NewDecoder(keys32[i]).Float32(Asc)
+			if err != nil || math.Float32bits(got) != math.Float32bits(float32(v)) {
+				t.Errorf("decoding float32 %v returned %v, %v", float32(v), got, err)
+			}
+		}
+		if !slices.IsSortedFunc(keys32, bytes.Compare) {
+			t.Errorf("float32 keys are not sorted: %x", keys32)
+		}
+	}
+}
+
+func TestBytes(t *testing.T) {
+	for _, o := range []Order{Asc, Desc} {
+		key := AppendBytes(nil, []byte{}, o)
+		key = AppendBytes(key, []byte{0, 1, 0xFF, 0}, o)
+		key = AppendUint64(key, 42, o)
+		d := NewDecoder(key)
+		if b, err := d.Bytes(o); err != nil || b == nil || len(b) != 0 {
+			t.Errorf("decoding empty bytes in order %v returned %v, %v", o, b, err)
+		}
+		if b, err := d.Bytes(o); err != nil || !bytes.Equal(b, []byte{0, 1, 0xFF, 0}) {
+			t.Errorf("decoding bytes in order %v returned %v, %v", o, b, err)
+		}
+		if v, err := d.Uint64(o); err != nil || v != 42 {
+			t.Errorf("decoding uint64 in order %v returned %v, %v", o, v, err)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Key    []byte
+		Decode func(d *Decoder) error
+		Want   error
+	}{
+		{"short uint64", []byte{1, 2, 3}, func(d *Decoder) error { _, err := d.Uint64(Asc); return err }, ErrShortKey},
+		{"short time", make([]byte, 11), func(d *Decoder) error { _, err := d.Time(Asc); return err }, ErrShortKey},
+		{"unterminated string", []byte("abc"), func(d *Decoder) error { _, err := d.String(Asc); return err }, ErrShortKey},
+		{"trailing escape", []byte{'a', 0}, func(d *Decoder) error { _, err := d.String(Asc); return err }, ErrShortKey},
+		{"invalid escape", []byte{'a', 0, 2}, func(d *Decoder) error { _, err := d.String(Asc); return err }, ErrInvalidKey},
+		{"invalid bool", []byte{2}, func(d *Decoder) error { _, err := d.Bool(Asc); return err }, ErrInvalidKey},
+		{"invalid nanoseconds", append(make([]byte, 8), 0xFF, 0xFF, 0xFF, 0xFF), func(d *Decoder) error { _, err := d.Time(Asc); return err }, ErrInvalidKey},
+	}
+	for _, tt := range tests {
+		if err := tt.Decode(NewDecoder(tt.Key)); err != tt.Want {
+			t.Errorf("decoding %s returned %v, want %v", tt.Name, err, tt.Want)
+		}
+	}
+	if v, err := NewDecoder([]byte{1, 2, 3}).Int64(Asc); v != 0 || err != ErrShortKey {
+		t.Errorf("decoding short int64 returned %v, %v", v, err)
+	}
+}
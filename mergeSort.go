@@ -2,7 +2,11 @@ package sort
 
 import (
 	"cmp"
+	"context"
+	"math/bits"
 	"slices"
+
+	"github.com/fossoreslp/go-sort/internal/progress"
 )
 
 // MergeSort implements merge sort for all ordered primitive types.
@@ -24,6 +28,60 @@ func MergeSort[T cmp.Ordered](items []T) []T {
 	return items
 }
 
+// MergeSortFunc is the equivalent of MergeSort for any type of data, using the comparison function cmp to determine the order.
+// The function should return a negative number when a < b, a positive number when a > b and zero when a == b.
+// Just like MergeSort, it is stable, making it suitable for sorting structs by one of their fields without reordering items with equal keys.
+func MergeSortFunc[T any](items []T, cmp func(a, b T) int) []T {
+	if len(items) < 2 {
+		return items
+	}
+
+	// Create a copy of the data since merge sort cannot easily operate in-place
+	tmp := make([]T, len(items))
+	copy(tmp, items)
+
+	// Sort with alternating source and destination
+	mergeSortFunc(tmp, items, cmp)
+
+	return items
+}
+
+// MergeSortCtx is the equivalent of MergeSort that stops sorting when ctx is cancelled, returning the context's error.
+// Cancellation is checked before merging large parts of the data, which adds no measurable overhead.
+// After cancellation, items contains the original elements in an unspecified order.
+// Progress is reported after merging large parts of the data if a callback has been registered using WithProgress.
+func MergeSortCtx[T cmp.Ordered](ctx context.Context, items []T) error {
+	if len(items) < 2 {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	tmp := make([]T, len(items))
+	copy(tmp, items)
+
+	p := newProgress(ctx, mergeWork(len(items)))
+	if !mergeSortCtx(tmp, items, ctx.Done(), p) {
+		return ctx.Err()
+	}
+	p.Finish()
+	return nil
+}
+
+// cancelCheckSize is the minimum number of items merged at once for which cancellation is checked and progress is reported.
+// Smaller parts are sorted without any checks, which keeps the overhead negligible while still reacting quickly.
+const cancelCheckSize = 1 << 12
+
+// mergeWork estimates the work required by mergeSortCtx to sort n items in the same units it reports progress in.
+// Parts smaller than cancelCheckSize count as n·log₂(n), larger parts count their merge as n in addition to the work of both halves.
+func mergeWork(n int) float64 {
+	if n < cancelCheckSize {
+		return float64(n) * float64(bits.Len(uint(n)))
+	}
+	return mergeWork(n/2) + mergeWork(n-n/2) + float64(n)
+}
+
 // mergeSort in the recursive sorting part of merge sort and will call itself for each half of the supplied data and then mergeSortedSets to merge the results.
 func mergeSort[T cmp.Ordered](src, dst []T) {
 	if len(src) < 2 {
@@ -41,6 +99,90 @@ func mergeSort[T cmp.Ordered](src, dst []T) {
 	mergeSortedSets(src[:mid], src[mid:], dst)
 }
 
+// mergeSortFunc is the equivalent of mergeSort using a comparison function.
+func mergeSortFunc[T any](src, dst []T, cmp func(a, b T) int) {
+	if len(src) < 2 {
+		return
+	}
+
+	// Find the midpoint
+	mid := len(src) / 2
+
+	// Recursively sort the two halves with swapped src and dst
+	mergeSortFunc(dst[:mid], src[:mid], cmp)
+	mergeSortFunc(dst[mid:], src[mid:], cmp)
+
+	// Merge the sorted halves from src into dst
+	mergeSortedSetsFunc(src[:mid], src[mid:], dst, cmp)
+}
+
+// mergeSortCtx is the equivalent of mergeSort used by MergeSortCtx, returning false if sorting was cancelled by closing done.
+// Cancellation is only checked between sorting the halves and merging them, when both src and dst contain all of their original elements.
+// It is kept separate from mergeSort to avoid any overhead when cancellation is not used.
+func mergeSortCtx[T cmp.Ordered](src, dst []T, done <-chan struct{}, p *progress.Progress) bool {
+	// Small parts are sorted in one go since checking them would only add overhead
+	if len(src) < cancelCheckSize {
+		mergeSort(src, dst)
+		p.Add(mergeWork(len(src)))
+		return true
+	}
+
+	// Find the midpoint
+	mid := len(src) / 2
+
+	// Recursively sort the two halves with swapped src and dst
+	if !mergeSortCtx(dst[:mid], src[:mid], done, p) || !mergeSortCtx(dst[mid:], src[mid:], done, p) {
+		return false
+	}
+
+	if cancelled(done) {
+		return false
+	}
+
+	// Merge the sorted halves from src into dst
+	mergeSortedSets(src[:mid], src[mid:], dst)
+	p.Add(float64(len(src)))
+	return true
+}
+
+// mergeSortFuncCtx is the equivalent of mergeSortCtx using a comparison function.
+func mergeSortFuncCtx[T any](src, dst []T, cmp func(a, b T) int, done <-chan struct{}, p *progress.Progress) bool {
+	// Small parts are sorted in one go since checking them would only add overhead
+	if len(src) < cancelCheckSize {
+		mergeSortFunc(src, dst, cmp)
+		p.Add(mergeWork(len(src)))
+		return true
+	}
+
+	// Find the midpoint
+	mid := len(src) / 2
+
+	// Recursively sort the two halves with swapped src and dst
+	if !mergeSortFuncCtx(dst[:mid], src[:mid], cmp, done, p) || !mergeSortFuncCtx(dst[mid:], src[mid:], cmp, done, p) {
+		return false
+	}
+
+	if cancelled(done) {
+		return false
+	}
+
+	// Merge the sorted halves from src into dst
+	mergeSortedSetsFunc(src[:mid], src[mid:], dst, cmp)
+	p.Add(float64(len(src)))
+	return true
+}
+
+// cancelled reports whether done has been closed without blocking.
+// A nil channel is never closed, so the check is cheap when cancellation is not used.
+func cancelled(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
 // MergeSortedSets merges two already sorted slices very efficiently.
 // It is used as part of merge sort but can also be useful when inserting multiple elements into a sorted slice (though the elements to insert first have to be sorted) or when combining the results of distributed sorting algoritms.
 func MergeSortedSets[T cmp.Ordered](a, b []T) []T {
@@ -55,6 +197,20 @@ func MergeSortedSets[T cmp.Ordered](a, b []T) []T {
 	return buf
 }
 
+// MergeSortedSetsFunc is the equivalent of MergeSortedSets for any type of data, using the comparison function cmp to determine the order.
+// Both slices have to be sorted according to the same comparison function.
+func MergeSortedSetsFunc[T any](a, b []T, cmp func(a, b T) int) []T {
+	if len(a) == 0 {
+		return slices.Clone(b)
+	}
+	if len(b) == 0 {
+		return slices.Clone(a)
+	}
+	buf := make([]T, len(a)+len(b))
+	mergeSortedSetsFunc(a, b, buf, cmp)
+	return buf
+}
+
 // mergeSortedSets implements the actual sorting logic but requires a target buffer to be supplied, making it unsuitable as the public interface.
 // It is used by mergeSort and wrapped by MergeSortedSets for external use.
 func mergeSortedSets[T cmp.Ordered](a, b []T, buf []T) {
@@ -62,7 +218,31 @@ func mergeSortedSets[T cmp.Ordered](a, b []T, buf []T) {
 	aPos := 0
 	bPos := 0
 	for i := range length {
-		if a[aPos] <= b[bPos] {
+		if !less(b[bPos], a[aPos]) {
+			buf[i] = a[aPos]
+			aPos++
+			if aPos == len(a) {
+				copy(buf[i+1:], b[bPos:])
+				return
+			}
+		} else {
+			buf[i] = b[bPos]
+			bPos++
+			if bPos == len(b) {
+				copy(buf[i+1:], a[aPos:])
+				return
+			}
+		}
+	}
+}
+
+// mergeSortedSetsFunc is the equivalent of mergeSortedSets using a comparison function.
+func mergeSortedSetsFunc[T any](a, b []T, buf []T, cmp func(a, b T) int) {
+	length := len(a) + len(b)
+	aPos := 0
+	bPos := 0
+	for i := range length {
+		if cmp(a[aPos], b[bPos]) <= 0 {
 			buf[i] = a[aPos]
 			aPos++
 			if aPos == len(a) {
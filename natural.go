@@ -0,0 +1,68 @@
+package sort
+
+import (
+	"cmp"
+	"strings"
+)
+
+// NaturalCompare compares two strings in natural order, treating runs of ASCII digits as numbers so that "item2" is ordered before "item10".
+// Numbers are compared by their value regardless of their length, so they are never limited to the range of an integer type.
+// All other characters are compared by their Unicode code points, which is the same as comparing their UTF-8 encoding byte by byte.
+// If two strings only differ in the leading zeros of their numbers, the first number with fewer leading zeros is ordered first, so that "a1" is ordered before "a01".
+// The result is only 0 if both strings are identical.
+func NaturalCompare(a, b string) int {
+	// The first difference in leading zeros is only used if the strings are otherwise equal
+	tiebreak := 0
+	for len(a) > 0 && len(b) > 0 {
+		if !isDigit(a[0]) || !isDigit(b[0]) {
+			if a[0] != b[0] {
+				return cmp.Compare(a[0], b[0])
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		lenA, lenB := digitRun(a), digitRun(b)
+		numA, numB := strings.TrimLeft(a[:lenA], "0"), strings.TrimLeft(b[:lenB], "0")
+		// Without leading zeros, longer numbers are always larger
+		if c := cmp.Compare(len(numA), len(numB)); c != 0 {
+			return c
+		}
+		if c := strings.Compare(numA, numB); c != 0 {
+			return c
+		}
+		if tiebreak == 0 {
+			tiebreak = cmp.Compare(lenA, lenB)
+		}
+		a, b = a[lenA:], b[lenB:]
+	}
+	if c := cmp.Compare(len(a), len(b)); c != 0 {
+		return c
+	}
+	return tiebreak
+}
+
+// NaturalSort sorts strings in natural order as defined by NaturalCompare.
+func NaturalSort(items []string) []string {
+	return SortFunc(items, NaturalCompare)
+}
+
+// NaturalSortStable is the equivalent of NaturalSort using a stable algorithm.
+// Since NaturalCompare only considers identical strings equal, the result is the same as that of NaturalSort.
+func NaturalSortStable(items []string) []string {
+	return SortStableFunc(items, NaturalCompare)
+}
+
+// isDigit reports whether c is an ASCII digit.
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// digitRun returns the number of ASCII digits at the start of s.
+func digitRun(s string) int {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return i
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,130 @@
+package sort
+
+import (
+	"cmp"
+	"net/netip"
+	"slices"
+)
+
+// SortAddrs sorts IP addresses in the same order as netip.Addr.Compare using radix sort.
+// Invalid addresses are ordered first, followed by IPv4 and then IPv6 addresses, each ordered by their bytes and zone.
+func SortAddrs(items []netip.Addr) []netip.Addr {
+	keys := make([][20]byte, len(items))
+	for i, a := range items {
+		keys[i] = addrKey(a)
+	}
+	sortNetip(items, keys, netip.Addr.Compare)
+	return items
+}
+
+// SortPrefixes sorts IP prefixes in the order of ComparePrefixes using radix sort.
+func SortPrefixes(items []netip.Prefix) []netip.Prefix {
+	keys := make([][20]byte, len(items))
+	for i, p := range items {
+		keys[i] = addrKey(p.Masked().Addr())
+		// Invalid prefixes have a length of -1
+		keys[i][17] = byte(p.Bits() + 1)
+	}
+	sortNetip(items, keys, ComparePrefixes)
+	return items
+}
+
+// ComparePrefixes compares two prefixes in the same way as netip.Prefix.Compare, which is only available starting with Go 1.26.
+// Prefixes are ordered by their masked address like addresses, then by their prefix length and finally by their unmasked address.
+// Therefore a prefix is always ordered before all prefixes it contains.
+func ComparePrefixes(a, b netip.Prefix) int {
+	if c := a.Masked().Addr().Compare(b.Masked().Addr()); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Bits(), b.Bits()); c != 0 {
+		return c
+	}
+	return a.Addr().Compare(b.Addr())
+}
+
+// AggregatePrefixes sorts prefixes and combines them into the smallest set of prefixes covering the same addresses, returning the shortened slice.
+// Prefixes contained in others are removed and adjacent prefixes of the same length are joined, e.g. 10.0.0.0/25 and 10.0.0.128/25 into 10.0.0.0/24.
+// The result only contains masked prefixes, while invalid prefixes are dropped.
+// IPv4 and IPv4-mapped IPv6 prefixes are treated as separate address families, just like netip.Prefix.Overlaps does.
+func AggregatePrefixes(items []netip.Prefix) []netip.Prefix {
+	for i, p := range items {
+		items[i] = p.Masked()
+	}
+	return aggregateSortedPrefixes(SortPrefixes(items))
+}
+
+// MergePrefixes merges two slices of aggregated prefixes, like those returned by AggregatePrefixes, into a new aggregated slice.
+// It works like MergeSortedSets, but afterwards aggregates prefixes of both slices in the same way as AggregatePrefixes does.
+func MergePrefixes(a, b []netip.Prefix) []netip.Prefix {
+	return aggregateSortedPrefixes(MergeSortedSetsFunc(a, b, ComparePrefixes))
+}
+
+// addrKey maps an address to a key ordered like netip.Addr.Compare except for its zone.
+// The first byte holds the address family, followed by the 16 byte representation of the address, leaving the last three bytes unused.
+func addrKey(a netip.Addr) (key [20]byte) {
+	switch {
+	case a.Is4():
+		key[0] = 1
+	case a.Is6():
+		key[0] = 2
+	}
+	ip := a.As16()
+	copy(key[1:], ip[:])
+	return key
+}
+
+// sortNetip sorts items by their keys using radix sort and afterwards sorts items with equal keys using compare.
+// This is necessary for parts that do not fit into the keys, like zones, which are usually identical for all items.
+func sortNetip[T any](items []T, keys [][20]byte, compare func(a, b T) int) {
+	if len(items) < 2 {
+		return
+	}
+	msdRadixSortArrays(keys, items, make([][20]byte, len(keys)), make([]T, len(items)), 0)
+	for start := 0; start < len(keys); {
+		end := start + 1
+		for end < len(keys) && keys[end] == keys[start] {
+			end++
+		}
+		if end-start > 1 {
+			slices.SortStableFunc(items[start:end], compare)
+		}
+		start = end
+	}
+}
+
+// aggregateSortedPrefixes aggregates sorted and masked prefixes in-place, returning the shortened slice.
+// Since a prefix is ordered before all prefixes it contains, they are only ever contained in the last prefix of the result.
+// Joining two adjacent prefixes may enable joining their parent with the previous one, so this is repeated as long as possible.
+func aggregateSortedPrefixes(items []netip.Prefix) []netip.Prefix {
+	result := items[:0]
+	for _, p := range items {
+		if !p.IsValid() {
+			continue
+		}
+		if n := len(result); n > 0 && result[n-1].Bits() <= p.Bits() && result[n-1].Contains(p.Addr()) {
+			continue
+		}
+		result = append(result, p)
+		for n := len(result); n > 1; n-- {
+			parent, ok := joinPrefixes(result[n-2], result[n-1])
+			if !ok {
+				break
+			}
+			result = append(result[:n-2], parent)
+		}
+	}
+	clear(items[len(result):])
+	return result
+}
+
+// joinPrefixes returns the parent prefix of a and b if they are the two halves of it.
+func joinPrefixes(a, b netip.Prefix) (netip.Prefix, bool) {
+	if a == b || a.Bits() != b.Bits() || a.Bits() == 0 || a.Addr().BitLen() != b.Addr().BitLen() {
+		return netip.Prefix{}, false
+	}
+	parent, _ := a.Addr().Prefix(a.Bits() - 1)
+	if !parent.Contains(b.Addr()) {
+		return netip.Prefix{}, false
+	}
+	return parent, true
+}
@@ -0,0 +1,26 @@
+package sort
+
+import (
+	"context"
+
+	"github.com/fossoreslp/go-sort/internal/progress"
+)
+
+type progressKey struct{}
+
+// WithProgress returns a context that makes the sorting functions accepting a context report their progress to fn.
+// The completed fraction is passed as a value between 0 and 1 and reports are limited to steps of at least one percent, so the callback is called at most about 100 times per sort.
+// The callback is called synchronously from the sorting goroutine and should return quickly.
+// RadixSortCtx reports after every pass over the data while MergeSortCtx reports after merging large parts of the data.
+func WithProgress(ctx context.Context, fn func(fraction float64)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// newProgress returns a progress for the given total amount of work if ctx carries a callback registered using WithProgress and nil otherwise.
+func newProgress(ctx context.Context, total float64) *progress.Progress {
+	fn, _ := ctx.Value(progressKey{}).(func(float64))
+	if total <= 0 {
+		return nil
+	}
+	return progress.New(fn, total)
+}
@@ -7,8 +7,27 @@ import "cmp"
 //
 // Deprecated: use slices.Sort instead which provides a more optimized implementation of quicksort.
 func QuickSort[T cmp.Ordered](items []T) []T {
+	nans := partitionNaNs(items)
+	quickSort(items[nans:])
+	return items
+}
+
+// partitionNaNs moves all NaNs to the front of items and returns their count.
+// The remaining items can then be sorted using plain comparisons.
+func partitionNaNs[T cmp.Ordered](items []T) int {
+	n := 0
+	for i, v := range items {
+		if isNaN(v) {
+			items[n], items[i] = items[i], items[n]
+			n++
+		}
+	}
+	return n
+}
+
+func quickSort[T cmp.Ordered](items []T) {
 	if len(items) < 2 {
-		return items
+		return
 	}
 	pivot := items[0]
 	i := 0
@@ -21,9 +40,41 @@ func QuickSort[T cmp.Ordered](items []T) []T {
 			j--
 		}
 		if i >= j {
-			QuickSort(items[:j+1])
-			QuickSort(items[j+1:])
-			return items
+			quickSort(items[:j+1])
+			quickSort(items[j+1:])
+			return
+		}
+		items[i], items[j] = items[j], items[i]
+		i++
+		j--
+	}
+}
+
+// QuickSortFunc is the equivalent of QuickSort for any type of data, using the comparison function cmp to determine the order.
+// Like QuickSort it is not stable, and slices.SortFunc provides a more optimized implementation.
+func QuickSortFunc[T any](items []T, cmp func(a, b T) int) []T {
+	quickSortFunc(items, cmp)
+	return items
+}
+
+func quickSortFunc[T any](items []T, cmp func(a, b T) int) {
+	if len(items) < 2 {
+		return
+	}
+	pivot := items[0]
+	i := 0
+	j := len(items) - 1
+	for {
+		for cmp(items[i], pivot) < 0 {
+			i++
+		}
+		for cmp(items[j], pivot) > 0 {
+			j--
+		}
+		if i >= j {
+			quickSortFunc(items[:j+1], cmp)
+			quickSortFunc(items[j+1:], cmp)
+			return
 		}
 		items[i], items[j] = items[j], items[i]
 		i++
@@ -2,9 +2,12 @@ package sort
 
 import (
 	"cmp"
+	"context"
 	"math/bits"
 	"slices"
 	"unsafe"
+
+	"github.com/fossoreslp/go-sort/internal/progress"
 )
 
 // RadixSort implements radix sort using byte-by-byte sorting with 256 buckets for all integer types.
@@ -14,30 +17,59 @@ import (
 // Signed integers are handled by flipping the sign bit before and after sorting and treating them as unsigned integers.
 // The computational complexity is O(n) with a space requirement of O(2n).
 func RadixSort[T cmp.Ordered](items []T) []T {
+	radixSort(items, nil, nil)
+	return items
+}
+
+// RadixSortCtx is the equivalent of RadixSort that stops sorting when ctx is cancelled, returning the context's error.
+// Cancellation is checked before every pass over the data, so it is noticed within the time a single pass takes.
+// After cancellation, items contains the original elements in an unspecified order.
+// Progress is reported after every pass if a callback has been registered using WithProgress.
+// Types not supported by radix sort are sorted using MergeSortCtx instead of slices.Sort since the latter cannot be cancelled.
+func RadixSortCtx[T cmp.Ordered](ctx context.Context, items []T) error {
+	if !isInteger[T]() {
+		return MergeSortCtx(ctx, items)
+	}
+	var val T
+	p := newProgress(ctx, float64(unsafe.Sizeof(val)))
+	if !radixSort(items, ctx.Done(), p) {
+		return ctx.Err()
+	}
+	p.Finish()
+	return nil
+}
+
+// radixSort implements RadixSort and RadixSortCtx, returning false if sorting was cancelled by closing done.
+// Each byte pass is reported to p as one unit of work.
+func radixSort[T cmp.Ordered](items []T, done <-chan struct{}, p *progress.Progress) bool {
 	// No need to sort slices with less than two items
 	if len(items) < 2 {
-		return items
+		return true
 	}
+	if cancelled(done) {
+		return false
+	}
+	ok := true
 	var val T
 	switch any(val).(type) {
 	case uint64:
-		radixSortUint(any(items).([]uint64))
+		ok = radixSortUint(any(items).([]uint64), done, p)
 	case uint32:
-		radixSortUint(any(items).([]uint32))
+		ok = radixSortUint(any(items).([]uint32), done, p)
 	case uint16:
-		radixSortUint(any(items).([]uint16))
+		ok = radixSortUint(any(items).([]uint16), done, p)
 	case uint8:
 		countingSort(any(items).([]uint8))
 	case uint:
-		radixSortUint(any(items).([]uint))
+		ok = radixSortUint(any(items).([]uint), done, p)
 	case uintptr:
-		radixSortUint(any(items).([]uintptr))
+		ok = radixSortUint(any(items).([]uintptr), done, p)
 	case int64:
 		uintslice := unsafe.Slice((*uint64)(unsafe.Pointer(unsafe.SliceData(items))), len(items))
 		for i := range uintslice {
 			uintslice[i] ^= 0x8000000000000000
 		}
-		radixSortUint(uintslice)
+		ok = radixSortUint(uintslice, done, p)
 		for i := range uintslice {
 			uintslice[i] ^= 0x8000000000000000
 		}
@@ -46,7 +78,7 @@ func RadixSort[T cmp.Ordered](items []T) []T {
 		for i := range uintslice {
 			uintslice[i] ^= 0x80000000
 		}
-		radixSortUint(uintslice)
+		ok = radixSortUint(uintslice, done, p)
 		for i := range uintslice {
 			uintslice[i] ^= 0x80000000
 		}
@@ -55,7 +87,7 @@ func RadixSort[T cmp.Ordered](items []T) []T {
 		for i := range uintslice {
 			uintslice[i] ^= 0x8000
 		}
-		radixSortUint(uintslice)
+		ok = radixSortUint(uintslice, done, p)
 		for i := range uintslice {
 			uintslice[i] ^= 0x8000
 		}
@@ -74,50 +106,20 @@ func RadixSort[T cmp.Ordered](items []T) []T {
 		for i := range uintslice {
 			uintslice[i] ^= mask
 		}
-		radixSortUint(uintslice)
+		ok = radixSortUint(uintslice, done, p)
 		for i := range uintslice {
 			uintslice[i] ^= mask
 		}
 	default:
 		slices.Sort(items)
 	}
-	return items
+	return ok
 }
 
-// radixSortUint implements radix sort for all multi-byte unsigned integer types, adapting to their respective sizes
-func radixSortUint[T uint64 | uint32 | uint16 | uint | uintptr](items []T) []T {
-	tmp := make([]T, len(items))
-	src := items
-	dst := tmp
-	var val T
-	bits := int(unsafe.Sizeof(val)) * 8
-
-	// Loop over the individual bytes of the unsigned integer type
-	for shift := 0; shift < bits; shift += 8 {
-		// Create buckets and count items
-		bucket := [256]int{}
-		for i := range items {
-			bucket[int(src[i]>>shift&0xFF)]++
-		}
-
-		// Add count from previous bucket
-		// The bucket values are used as indices for the sorted array and therefore have to be higher for buckets with higher sort values.
-		for i := 1; i < 256; i++ {
-			bucket[i] += bucket[i-1]
-		}
-
-		// Use the buckets indices when filling the sorted array
-		for i := len(items) - 1; i >= 0; i-- {
-			v := &bucket[int(src[i]>>shift&0xFF)]
-			*v--
-			dst[*v] = src[i]
-		}
-
-		// Swap source and destination for the next pass
-		src, dst = dst, src
-	}
-
-	return items
+// radixSortUint implements radix sort for all multi-byte unsigned integer types, adapting to their respective sizes.
+// It returns false if sorting was cancelled by closing done, in which case items still contains all of the original elements.
+func radixSortUint[T uint64 | uint32 | uint16 | uint | uintptr](items []T, done <-chan struct{}, p *progress.Progress) bool {
+	return radixSortUintPairs[T, struct{}](items, nil, done, p)
 }
 
 func countingSort(items []uint8) []uint8 {
@@ -147,3 +149,156 @@ func countingSort(items []uint8) []uint8 {
 
 	return items
 }
+
+// radixSortUintPairs implements radix sort for unsigned integer keys while moving the values at the same positions along with their keys.
+// vals may be nil to sort only the keys. The sort is stable, which allows it to be used for successive passes over different keys.
+// Passes in which all keys share the same byte are skipped since they would not change the order.
+// It returns false if sorting was cancelled by closing done, in which case both slices still contain all of the original elements in matching order.
+func radixSortUintPairs[K uint64 | uint32 | uint16 | uint8 | uint | uintptr, V any](keys []K, vals []V, done <-chan struct{}, p *progress.Progress) bool {
+	srcK, dstK := keys, make([]K, len(keys))
+	srcV, dstV := vals, make([]V, len(vals))
+	moveVals := vals != nil
+	var key K
+	bits := int(unsafe.Sizeof(key)) * 8
+
+	// Loop over the individual bytes of the unsigned integer type
+	for shift := 0; shift < bits; shift += 8 {
+		// Between passes src always contains all elements, so it only has to be copied back when it is the temporary buffer
+		if cancelled(done) {
+			radixCopyBack(keys, srcK, vals, srcV)
+			return false
+		}
+
+		// Create buckets and count items
+		bucket := [256]int{}
+		for i := range srcK {
+			bucket[int(srcK[i]>>shift&0xFF)]++
+		}
+
+		// A single bucket containing all items means this byte does not influence the order
+		if bucket[int(srcK[0]>>shift&0xFF)] == len(srcK) {
+			p.Add(1)
+			continue
+		}
+
+		// Add count from previous bucket
+		// The bucket values are used as indices for the sorted array and therefore have to be higher for buckets with higher sort values.
+		for i := 1; i < 256; i++ {
+			bucket[i] += bucket[i-1]
+		}
+
+		// Use the buckets indices when filling the sorted arrays
+		for i := len(srcK) - 1; i >= 0; i-- {
+			v := &bucket[int(srcK[i]>>shift&0xFF)]
+			*v--
+			dstK[*v] = srcK[i]
+			if moveVals {
+				dstV[*v] = srcV[i]
+			}
+		}
+
+		// Swap source and destination for the next pass
+		srcK, dstK = dstK, srcK
+		srcV, dstV = dstV, srcV
+		p.Add(1)
+	}
+
+	radixCopyBack(keys, srcK, vals, srcV)
+	return true
+}
+
+// radixCopyBack copies the keys and values back from the temporary buffers if they hold the result, which depends on the number of passes that were not skipped.
+func radixCopyBack[K, V any](keys, srcK []K, vals, srcV []V) {
+	if len(keys) > 0 && &srcK[0] != &keys[0] {
+		copy(keys, srcK)
+		copy(vals, srcV)
+	}
+}
+
+// radixSortIntPairs sorts signed integer keys as unsigned integers of the same size, flipping the sign bit before and after so that negative keys end up in the lower buckets.
+func radixSortIntPairs[S int64 | int32 | int16 | int8 | int, U uint64 | uint32 | uint16 | uint8 | uint, V any](keys []S, vals []V) {
+	uintslice := unsafe.Slice((*U)(unsafe.Pointer(unsafe.SliceData(keys))), len(keys))
+	var mask U = 1
+	mask <<= unsafe.Sizeof(mask)*8 - 1
+	for i := range uintslice {
+		uintslice[i] ^= mask
+	}
+	radixSortUintPairs(uintslice, vals, nil, nil)
+	for i := range uintslice {
+		uintslice[i] ^= mask
+	}
+}
+
+// radixSortStringPairs implements a stable most significant digit radix sort for string keys while moving the values at the same positions along with their keys.
+// It is used for keys created by an order-preserving encoding, where comparing the bytes of the keys yields the desired order.
+func radixSortStringPairs[V any](keys []string, vals []V) {
+	msdRadixSort(keys, vals, make([]string, len(keys)), make([]V, len(vals)), 0)
+}
+
+// msdInsertionSize is the maximum number of items for which msdRadixSort falls back to insertion sort.
+const msdInsertionSize = 32
+
+// msdRadixSort sorts keys and vals by the bytes of the keys starting at depth, which are known to have an identical prefix before it.
+// Items are distributed into 257 buckets, the first one holding the keys that end at depth and the others one for each byte value.
+// The temporary slices have to be the same length as keys and vals.
+func msdRadixSort[V any](keys []string, vals []V, tmpK []string, tmpV []V, depth int) {
+	for {
+		if len(keys) <= msdInsertionSize {
+			for i := range keys {
+				for position := i; position > 0 && keys[position-1][depth:] > keys[position][depth:]; position-- {
+					keys[position], keys[position-1] = keys[position-1], keys[position]
+					vals[position], vals[position-1] = vals[position-1], vals[position]
+				}
+			}
+			return
+		}
+
+		// Create buckets and count items
+		bucket := [258]int{}
+		for _, k := range keys {
+			bucket[msdBucket(k, depth)+1]++
+		}
+
+		// A single bucket containing all items means this byte does not influence the order
+		if first := msdBucket(keys[0], depth); bucket[first+1] == len(keys) {
+			if first == 0 {
+				// All keys are identical
+				return
+			}
+			depth++
+			continue
+		}
+
+		// Add count from previous bucket, shifted by one so that each bucket starts at the end of the previous one
+		for i := 1; i < 258; i++ {
+			bucket[i] += bucket[i-1]
+		}
+
+		// Fill the temporary slices in the original order to keep the sort stable and copy them back
+		starts := bucket
+		for i, k := range keys {
+			b := &bucket[msdBucket(k, depth)]
+			tmpK[*b] = k
+			tmpV[*b] = vals[i]
+			*b++
+		}
+		copy(keys, tmpK[:len(keys)])
+		copy(vals, tmpV[:len(vals)])
+
+		// Sort the buckets individually by the following bytes, skipping the first one containing keys that have ended
+		for b := 1; b < 257; b++ {
+			if starts[b+1]-starts[b] > 1 {
+				msdRadixSort(keys[starts[b]:starts[b+1]], vals[starts[b]:starts[b+1]], tmpK, tmpV, depth+1)
+			}
+		}
+		return
+	}
+}
+
+// msdBucket returns the bucket of key at depth, which is 0 for keys that end before depth and the byte value plus one otherwise.
+func msdBucket(key string, depth int) int {
+	if depth >= len(key) {
+		return 0
+	}
+	return int(key[depth]) + 1
+}
@@ -0,0 +1,228 @@
+// Package records sorts files consisting of fixed-size binary records by integer keys at known offsets.
+//
+// Records are sorted using a least significant digit radix sort directly on the bytes of their keys, so no keys have to be decoded.
+// Records are always moved as a whole and the sort is stable, so records with equal keys retain their original order.
+package records
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	sort "github.com/fossoreslp/go-sort"
+	"github.com/fossoreslp/go-sort/extsort"
+)
+
+// Key describes an integer key stored at a fixed position in every record.
+type Key struct {
+	// Offset is the position of the first byte of the key in the record.
+	Offset int
+	// Length is the number of bytes of the key, which may be larger than 8 for e.g. 128-bit integers or hashes.
+	Length int
+	// Signed treats the key as a two's complement signed integer.
+	Signed bool
+	// LittleEndian treats the first byte as the least significant one instead of the most significant one.
+	LittleEndian bool
+	// Descending sorts in descending order of the key.
+	Descending bool
+}
+
+// ParseKey parses a key of the form OFFSET:LENGTH[:FLAGS], where FLAGS consists of the letters
+// s for signed, l for little endian and r for descending order, e.g. "8:4:sl".
+func ParseKey(s string) (Key, error) {
+	invalid := func(reason string) (Key, error) {
+		return Key{}, errors.New("records: invalid key " + strconv.Quote(s) + ": " + reason)
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return invalid("expected OFFSET:LENGTH[:FLAGS]")
+	}
+	var k Key
+	var err error
+	if k.Offset, err = strconv.Atoi(parts[0]); err != nil || k.Offset < 0 {
+		return invalid("invalid offset")
+	}
+	if k.Length, err = strconv.Atoi(parts[1]); err != nil || k.Length <= 0 {
+		return invalid("invalid length")
+	}
+	if len(parts) == 3 {
+		for _, f := range parts[2] {
+			switch f {
+			case 's':
+				k.Signed = true
+			case 'l':
+				k.LittleEndian = true
+			case 'r':
+				k.Descending = true
+			default:
+				return invalid("unknown flag " + strconv.QuoteRune(f))
+			}
+		}
+	}
+	return k, nil
+}
+
+// Sort sorts the records of the given size stored consecutively in data by the keys in-place.
+// Records are compared by the first key and only by the following ones if the previous keys are equal.
+func Sort(data []byte, size int, keys ...Key) error {
+	if err := validate(size, keys); err != nil {
+		return err
+	}
+	if len(data)%size != 0 {
+		return fmt.Errorf("records: data length %d is not a multiple of the record size %d", len(data), size)
+	}
+	records := make([][]byte, len(data)/size)
+	for i := range records {
+		records[i] = data[i*size : (i+1)*size : (i+1)*size]
+	}
+	radixSort(records, keys)
+	sorted := make([]byte, 0, len(data))
+	for _, r := range records {
+		sorted = append(sorted, r...)
+	}
+	copy(data, sorted)
+	return nil
+}
+
+// SortStream reads records of the given size from src until EOF, sorts them by the keys and writes them to dst.
+// If the records do not fit into the memory limit given by opts, sorted parts are written to temporary files and merged afterwards.
+// The Size and Sort options are set by SortStream and must not be set by the caller.
+func SortStream(dst io.Writer, src io.Reader, size int, keys []Key, opts extsort.Options[[]byte]) error {
+	if err := validate(size, keys); err != nil {
+		return err
+	}
+	opts.Size = func(r []byte) int { return len(r) + 24 }
+	opts.Sort = func(records [][]byte) { radixSort(records, keys) }
+	s := extsort.New(Compare(keys), codec(size), opts)
+	defer s.Close()
+
+	// Records are read in blocks to avoid allocating every record individually
+	r := bufio.NewReader(src)
+	var block []byte
+	for {
+		if len(block) == 0 {
+			block = make([]byte, size*max(1, 64<<10/size))
+		}
+		n, err := io.ReadFull(r, block[:size])
+		if err == io.EOF {
+			break
+		}
+		if err == io.ErrUnexpectedEOF {
+			return fmt.Errorf("records: input ends with an incomplete record of %d bytes", n)
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.Add(block[:size:size]); err != nil {
+			return err
+		}
+		block = block[size:]
+	}
+
+	w := bufio.NewWriter(dst)
+	if err := s.Each(func(r []byte) error {
+		_, err := w.Write(r)
+		return err
+	}); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// Compare returns a function comparing records by the keys, which produces the same order as Sort.
+func Compare(keys []Key) func(a, b []byte) int {
+	return func(a, b []byte) int {
+		for _, k := range keys {
+			for i := k.Length - 1; i >= 0; i-- {
+				pos, mask := k.byteAt(i)
+				if x, y := a[pos]^mask, b[pos]^mask; x != y {
+					if x < y {
+						return -1
+					}
+					return 1
+				}
+			}
+		}
+		return 0
+	}
+}
+
+// byteAt returns the position of the byte of the key with the given significance, with 0 being the least significant byte.
+// The mask has to be applied to the byte before comparing it, flipping the sign bit of signed keys and inverting all bits for descending keys.
+func (k Key) byteAt(significance int) (int, byte) {
+	pos := k.Offset + k.Length - 1 - significance
+	if k.LittleEndian {
+		pos = k.Offset + significance
+	}
+	var mask byte
+	if k.Signed && significance == k.Length-1 {
+		mask = 0x80
+	}
+	if k.Descending {
+		mask ^= 0xFF
+	}
+	return pos, mask
+}
+
+// validate checks that all keys are within records of the given size.
+func validate(size int, keys []Key) error {
+	if size <= 0 {
+		return fmt.Errorf("records: invalid record size %d", size)
+	}
+	if len(keys) == 0 {
+		return errors.New("records: no keys given")
+	}
+	for _, k := range keys {
+		if k.Offset < 0 || k.Length <= 0 || k.Offset+k.Length > size {
+			return fmt.Errorf("records: key with offset %d and length %d is not within records of %d bytes", k.Offset, k.Length, size)
+		}
+	}
+	return nil
+}
+
+// radixSort sorts records by the keys using a least significant digit radix sort.
+// The key bytes are combined into digits of up to 8 bytes, which are sorted along with the records using sort.SortPairs, starting with the least significant digit of the last key.
+// Since SortPairs is stable and skips bytes that are the same for all records, only bytes that influence the order are passed over.
+// Only the slices referring to the records are moved.
+func radixSort(records [][]byte, keys []Key) {
+	if len(records) < 2 {
+		return
+	}
+	digits := make([]uint64, len(records))
+	var pos [8]int
+	var mask [8]byte
+	for k := len(keys) - 1; k >= 0; k-- {
+		for low := 0; low < keys[k].Length; low += 8 {
+			// Positions are ordered from the most to the least significant byte of the digit
+			n := min(8, keys[k].Length-low)
+			for j := range n {
+				pos[j], mask[j] = keys[k].byteAt(low + n - 1 - j)
+			}
+			for i, r := range records {
+				var d uint64
+				for j := range n {
+					d = d<<8 | uint64(r[pos[j]]^mask[j])
+				}
+				digits[i] = d
+			}
+			sort.SortPairs(digits, records)
+		}
+	}
+}
+
+// codec writes records to runs without any framing since their size is fixed.
+type codec int
+
+func (c codec) Encode(w *bufio.Writer, r []byte) error {
+	_, err := w.Write(r)
+	return err
+}
+
+func (c codec) Decode(r *bufio.Reader) ([]byte, error) {
+	record := make([]byte, c)
+	_, err := io.ReadFull(r, record)
+	return record, err
+}
@@ -0,0 +1,145 @@
+package records
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/fossoreslp/go-sort/extsort"
+)
+
+// Records consist of a big endian uint32, a little endian int16, a signed big endian int8, a 16 byte hash and a uint32 index.
+const recordSize = 4 + 2 + 1 + 16 + 4
+
+func randomRecords(n int) []byte {
+	random := rand.New(rand.NewPCG(1, 2))
+	data := make([]byte, 0, n*recordSize)
+	for i := range n {
+		// Few distinct values make the following keys and the stability observable
+		data = binary.BigEndian.AppendUint32(data, uint32(random.IntN(4))<<24|uint32(random.IntN(3)))
+		data = binary.LittleEndian.AppendUint16(data, uint16(random.IntN(5)-2))
+		data = append(data, byte(random.IntN(5)-2))
+		hash := make([]byte, 16)
+		for j := range hash {
+			hash[j] = byte(random.IntN(2))
+		}
+		data = append(data, hash...)
+		data = binary.BigEndian.AppendUint32(data, uint32(i))
+	}
+	return data
+}
+
+func split(data []byte) [][]byte {
+	var records [][]byte
+	for r := range slices.Chunk(data, recordSize) {
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestSort(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Keys    []Key
+		Compare func(a, b []byte) int
+	}{
+		{"unsigned big endian", []Key{{Offset: 0, Length: 4}}, func(a, b []byte) int {
+			return cmp.Compare(binary.BigEndian.Uint32(a), binary.BigEndian.Uint32(b))
+		}},
+		{"signed little endian", []Key{{Offset: 4, Length: 2, Signed: true, LittleEndian: true}}, func(a, b []byte) int {
+			return cmp.Compare(int16(binary.LittleEndian.Uint16(a[4:])), int16(binary.LittleEndian.Uint16(b[4:])))
+		}},
+		{"descending signed byte", []Key{{Offset: 6, Length: 1, Signed: true, Descending: true}}, func(a, b []byte) int {
+			return cmp.Compare(int8(b[6]), int8(a[6]))
+		}},
+		{"hash", []Key{{Offset: 7, Length: 16}}, func(a, b []byte) int {
+			return bytes.Compare(a[7:23], b[7:23])
+		}},
+		{"multiple keys", []Key{{Offset: 6, Length: 1, Signed: true}, {Offset: 0, Length: 4, Descending: true}, {Offset: 4, Length: 2, LittleEndian: true}}, func(a, b []byte) int {
+			return cmp.Or(
+				cmp.Compare(int8(a[6]), int8(b[6])),
+				cmp.Compare(binary.BigEndian.Uint32(b), binary.BigEndian.Uint32(a)),
+				cmp.Compare(binary.LittleEndian.Uint16(a[4:]), binary.LittleEndian.Uint16(b[4:])),
+			)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			data := randomRecords(5000)
+			want := split(bytes.Clone(data))
+			slices.SortStableFunc(want, tt.Compare)
+			wantData := bytes.Join(want, nil)
+
+			if err := Sort(data, recordSize, tt.Keys...); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, wantData) {
+				t.Error("Sort result is not sorted stably")
+			}
+			if !slices.IsSortedFunc(split(data), Compare(tt.Keys)) {
+				t.Error("Compare does not match the order of Sort")
+			}
+
+			var out bytes.Buffer
+			opts := extsort.Options[[]byte]{MemoryLimit: 500 * (recordSize + 24), TempDir: t.TempDir()}
+			if err := SortStream(&out, bytes.NewReader(randomRecords(5000)), recordSize, tt.Keys, opts); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(out.Bytes(), wantData) {
+				t.Error("SortStream result is not sorted stably")
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	data := randomRecords(10)
+	if err := Sort(data[:len(data)-1], recordSize, Key{Length: 4}); err == nil {
+		t.Error("Sort accepted data with an incomplete record")
+	}
+	if err := SortStream(&bytes.Buffer{}, bytes.NewReader(data[:len(data)-1]), recordSize, []Key{{Length: 4}}, extsort.Options[[]byte]{}); err == nil {
+		t.Error("SortStream accepted data with an incomplete record")
+	}
+	invalid := [][]Key{
+		nil,
+		{{Offset: recordSize - 2, Length: 4}},
+		{{Offset: -1, Length: 4}},
+		{{Offset: 0, Length: 0}},
+	}
+	for _, keys := range invalid {
+		if err := Sort(data, recordSize, keys...); err == nil {
+			t.Errorf("Sort accepted keys %+v", keys)
+		}
+	}
+	if err := Sort(data, 0, Key{Length: 1}); err == nil {
+		t.Error("Sort accepted a record size of 0")
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		Input string
+		Want  Key
+		Valid bool
+	}{
+		{"0:8", Key{Offset: 0, Length: 8}, true},
+		{"8:4:sl", Key{Offset: 8, Length: 4, Signed: true, LittleEndian: true}, true},
+		{"2:2:r", Key{Offset: 2, Length: 2, Descending: true}, true},
+		{"2:2:", Key{Offset: 2, Length: 2}, true},
+		{"8", Key{}, false},
+		{"a:4", Key{}, false},
+		{"-1:4", Key{}, false},
+		{"0:0", Key{}, false},
+		{"0:4:x", Key{}, false},
+		{"0:4:s:l", Key{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseKey(tt.Input)
+		if (err == nil) != tt.Valid || got != tt.Want {
+			t.Errorf("ParseKey(%q) returned %+v, %v", tt.Input, got, err)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package sort
+
+import (
+	"cmp"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/fossoreslp/go-sort/keyenc"
+)
+
+// SemVer is a version number parsed according to Semantic Versioning 2.0.0.
+type SemVer struct {
+	Major uint64
+	Minor uint64
+	Patch uint64
+	// Prerelease contains the dot-separated pre-release identifiers, which is nil for a normal version.
+	Prerelease []string
+	// Build contains the dot-separated build metadata identifiers, which are ignored when comparing versions.
+	Build []string
+}
+
+// SemVerError describes a version that is not valid according to Semantic Versioning 2.0.0.
+type SemVerError struct {
+	Version string
+	Reason  string
+}
+
+func (e *SemVerError) Error() string {
+	return "sort: invalid semantic version " + strconv.Quote(e.Version) + ": " + e.Reason
+}
+
+// ParseSemVer parses a version according to Semantic Versioning 2.0.0.
+// The syntax is followed exactly, so prefixes such as "v" and missing minor or patch versions are rejected.
+// Numeric identifiers of the version core are limited to the range of uint64.
+func ParseSemVer(version string) (SemVer, error) {
+	var v SemVer
+	invalid := func(reason string) (SemVer, error) {
+		return SemVer{}, &SemVerError{Version: version, Reason: reason}
+	}
+
+	rest, build, hasBuild := strings.Cut(version, "+")
+	if hasBuild {
+		v.Build = strings.Split(build, ".")
+		for _, id := range v.Build {
+			if !isIdentifier(id) {
+				return invalid("invalid build metadata identifier " + strconv.Quote(id))
+			}
+		}
+	}
+	core, prerelease, hasPrerelease := strings.Cut(rest, "-")
+	if hasPrerelease {
+		v.Prerelease = strings.Split(prerelease, ".")
+		for _, id := range v.Prerelease {
+			if !isIdentifier(id) || isNumeric(id) && len(id) > 1 && id[0] == '0' {
+				return invalid("invalid pre-release identifier " + strconv.Quote(id))
+			}
+		}
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return invalid("version core must consist of major, minor and patch version")
+	}
+	for i, field := range []*uint64{&v.Major, &v.Minor, &v.Patch} {
+		if !isNumeric(parts[i]) || len(parts[i]) > 1 && parts[i][0] == '0' {
+			return invalid("invalid numeric identifier " + strconv.Quote(parts[i]))
+		}
+		n, err := strconv.ParseUint(parts[i], 10, 64)
+		if err != nil {
+			return invalid("numeric identifier " + parts[i] + " is out of range")
+		}
+		*field = n
+	}
+	return v, nil
+}
+
+// Compare compares two versions according to the precedence rules of Semantic Versioning 2.0.0.
+// Build metadata is ignored, so versions only differing in it are considered equal.
+func (v SemVer) Compare(w SemVer) int {
+	if c := cmp.Or(cmp.Compare(v.Major, w.Major), cmp.Compare(v.Minor, w.Minor), cmp.Compare(v.Patch, w.Patch)); c != 0 {
+		return c
+	}
+	// A pre-release version has lower precedence than the associated normal version
+	switch {
+	case v.Prerelease == nil && w.Prerelease == nil:
+		return 0
+	case v.Prerelease == nil:
+		return 1
+	case w.Prerelease == nil:
+		return -1
+	}
+	for i := 0; i < len(v.Prerelease) && i < len(w.Prerelease); i++ {
+		if c := compareIdentifier(v.Prerelease[i], w.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(v.Prerelease), len(w.Prerelease))
+}
+
+// AppendKey appends a key to dst that, when compared using bytes.Compare, orders versions the same way as Compare.
+func (v SemVer) AppendKey(dst []byte) []byte {
+	dst = keyenc.AppendUint64(dst, v.Major, keyenc.Asc)
+	dst = keyenc.AppendUint64(dst, v.Minor, keyenc.Asc)
+	dst = keyenc.AppendUint64(dst, v.Patch, keyenc.Asc)
+	// Identifiers are tagged so that numeric ones are ordered before alphanumeric ones and the end of the list before both
+	// Normal versions use a tag larger than both, ordering them after all pre-release versions
+	if v.Prerelease == nil {
+		return append(dst, 3)
+	}
+	for _, id := range v.Prerelease {
+		if isNumeric(id) {
+			// Numeric identifiers have no leading zeros, so longer ones are always larger
+			dst = append(dst, 1)
+			dst = keyenc.AppendUint64(dst, uint64(len(id)), keyenc.Asc)
+			dst = append(dst, id...)
+		} else {
+			dst = append(dst, 2)
+			dst = keyenc.AppendString(dst, id, keyenc.Asc)
+		}
+	}
+	return append(dst, 0)
+}
+
+// String returns the version in its canonical form.
+func (v SemVer) String() string {
+	s := strconv.FormatUint(v.Major, 10) + "." + strconv.FormatUint(v.Minor, 10) + "." + strconv.FormatUint(v.Patch, 10)
+	if v.Prerelease != nil {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if v.Build != nil {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// CompareSemVer parses and compares two versions according to the precedence rules of Semantic Versioning 2.0.0.
+// An error is returned if either of them is invalid.
+func CompareSemVer(a, b string) (int, error) {
+	va, err := ParseSemVer(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := ParseSemVer(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.Compare(vb), nil
+}
+
+// SortSemVer sorts versions according to the precedence rules of Semantic Versioning 2.0.0.
+// Every version is parsed only once and converted into a key that is sorted using radix sort.
+// The sort is stable, so versions with equal precedence, which only differ in their build metadata, retain their original order.
+// If any of the versions are invalid, the slice is not modified and an error describing all invalid versions is returned.
+func SortSemVer(versions []string) error {
+	keys := make([]string, len(versions))
+	var errs []error
+	var buf []byte
+	for i, version := range versions {
+		v, err := ParseSemVer(version)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		buf = v.AppendKey(buf[:0])
+		keys[i] = string(buf)
+	}
+	if errs != nil {
+		return errors.Join(errs...)
+	}
+	radixSortStringPairs(keys, versions)
+	return nil
+}
+
+// compareIdentifier compares two pre-release identifiers.
+// Numeric identifiers are compared numerically and have lower precedence than alphanumeric identifiers, which are compared in ASCII order.
+func compareIdentifier(a, b string) int {
+	numA, numB := isNumeric(a), isNumeric(b)
+	switch {
+	case numA && numB:
+		return cmp.Or(cmp.Compare(len(a), len(b)), strings.Compare(a, b))
+	case numA:
+		return -1
+	case numB:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// isIdentifier reports whether s is a non-empty string of ASCII alphanumerics and hyphens.
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !isDigit(c) && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// isNumeric reports whether s is a non-empty string of ASCII digits.
+func isNumeric(s string) bool {
+	return s != "" && digitRun(s) == len(s)
+}
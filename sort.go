@@ -0,0 +1,192 @@
+package sort
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sync/atomic"
+)
+
+// Thresholds control which algorithm Sort and SortStable choose for a given slice.
+// They can be serialized to JSON, allowing them to be calibrated once per machine type using Calibrate.
+type Thresholds struct {
+	// Insertion is the maximum length of a slice that is sorted using insertion sort.
+	Insertion int `json:"insertion"`
+	// Radix is the minimum length of a slice of integers that Sort sorts using radix sort instead of slices.Sort.
+	Radix int `json:"radix"`
+	// RadixStable is the minimum length of a slice of integers that SortStable sorts using radix sort instead of merge sort.
+	RadixStable int `json:"radix_stable"`
+	// Runs is the maximum number of ascending runs for which the runs are merged directly instead of sorting the entire slice.
+	Runs int `json:"runs"`
+}
+
+// DefaultThresholds are derived from the results in benchmark.md.
+// Radix sort overtakes slices.Sort at around 1000 items on both systems and merge sort at around 400 items.
+// Insertion sort is only competitive for very small slices that are not covered by the benchmarks.
+var DefaultThresholds = Thresholds{
+	Insertion:   12,
+	Radix:       1000,
+	RadixStable: 400,
+	Runs:        8,
+}
+
+var thresholds atomic.Pointer[Thresholds]
+
+// SetThresholds replaces the thresholds used by Sort and SortStable.
+// It is safe to call concurrently with sorting.
+func SetThresholds(t Thresholds) {
+	thresholds.Store(&t)
+}
+
+// CurrentThresholds returns the thresholds used by Sort and SortStable, which are DefaultThresholds unless SetThresholds has been called.
+func CurrentThresholds() Thresholds {
+	if t := thresholds.Load(); t != nil {
+		return *t
+	}
+	return DefaultThresholds
+}
+
+// Sort sorts a slice of ordered primitive types, choosing the algorithm based on the element type, the length of the slice and how much of it is already sorted.
+// Slices that consist of only a few ascending runs are merged directly, strictly descending slices are reversed and nearly sorted slices are sorted using insertion sort.
+// Otherwise small slices are sorted using insertion sort, large slices of integers using radix sort and everything else using slices.Sort.
+// The sort is not guaranteed to be stable, use SortStable if that is required.
+func Sort[T cmp.Ordered](items []T) []T {
+	return SortWith(CurrentThresholds(), items)
+}
+
+// SortWith is the equivalent of Sort using the supplied thresholds instead of the global ones.
+func SortWith[T cmp.Ordered](t Thresholds, items []T) []T {
+	if presorted(t, items) {
+		return items
+	}
+	if isInteger[T]() && len(items) >= t.Radix {
+		return RadixSort(items)
+	}
+	slices.Sort(items)
+	return items
+}
+
+// SortStable is the equivalent of Sort restricted to stable algorithms, therefore maintaining the order of elements that have the same value.
+// Large slices of integers are sorted using radix sort and everything else using merge sort.
+func SortStable[T cmp.Ordered](items []T) []T {
+	return SortStableWith(CurrentThresholds(), items)
+}
+
+// SortStableWith is the equivalent of SortStable using the supplied thresholds instead of the global ones.
+func SortStableWith[T cmp.Ordered](t Thresholds, items []T) []T {
+	if presorted(t, items) {
+		return items
+	}
+	if isInteger[T]() && len(items) >= t.RadixStable {
+		return RadixSort(items)
+	}
+	return MergeSort(items)
+}
+
+// SortFunc is the equivalent of Sort for any type of data, using the comparison function cmp to determine the order.
+// Small slices are sorted using insertion sort and everything else using slices.SortFunc.
+func SortFunc[T any](items []T, cmp func(a, b T) int) []T {
+	if len(items) <= CurrentThresholds().Insertion {
+		return InsertionSortFunc(items, cmp)
+	}
+	slices.SortFunc(items, cmp)
+	return items
+}
+
+// SortStableFunc is the equivalent of SortStable for any type of data, using the comparison function cmp to determine the order.
+// Small slices are sorted using insertion sort and everything else using merge sort.
+func SortStableFunc[T any](items []T, cmp func(a, b T) int) []T {
+	if len(items) <= CurrentThresholds().Insertion {
+		return InsertionSortFunc(items, cmp)
+	}
+	return MergeSortFunc(items, cmp)
+}
+
+// presorted handles all cases in which a cheap probe of the data allows sorting it without a general purpose algorithm.
+// It reports whether the slice has been sorted. All of the operations used are stable.
+func presorted[T cmp.Ordered](t Thresholds, items []T) bool {
+	if len(items) < 2 {
+		return true
+	}
+	if len(items) <= t.Insertion {
+		InsertionSort(items)
+		return true
+	}
+	// Counting stops as soon as the limit is exceeded, which happens within a few items for random data
+	if bounds := runBounds(items, t.Runs); bounds != nil {
+		mergeRuns(items, bounds)
+		return true
+	}
+	if isDescending(items) {
+		slices.Reverse(items)
+		return true
+	}
+	// Without any inverted pairs among O(√n) sampled pairs, the slice is likely to be nearly sorted, which insertion sort handles in linear time.
+	// Since the sample may miss disorder, insertion sort gives up after moving items as many times as there are items, leaving them to the general algorithms.
+	if _, inverted := sampleSortedness(items, int(math.Sqrt(float64(len(items))))); inverted == 0 {
+		return insertionSortLimit(items, len(items))
+	}
+	return false
+}
+
+// runBounds returns the start index of every ascending run in items followed by the length of items.
+// If there are more than limit runs, it stops counting and returns nil.
+func runBounds[T cmp.Ordered](items []T, limit int) []int {
+	bounds := []int{0}
+	for i := 1; i < len(items); i++ {
+		if less(items[i], items[i-1]) {
+			bounds = append(bounds, i)
+			if len(bounds) > limit {
+				return nil
+			}
+		}
+	}
+	return append(bounds, len(items))
+}
+
+// mergeRuns merges the ascending runs described by bounds pairwise until a single sorted run remains.
+func mergeRuns[T cmp.Ordered](items []T, bounds []int) {
+	if len(bounds) <= 2 {
+		return
+	}
+	src := items
+	dst := make([]T, len(items))
+	for len(bounds) > 2 {
+		next := bounds[:1]
+		i := 0
+		for ; i+2 < len(bounds); i += 2 {
+			mergeSortedSets(src[bounds[i]:bounds[i+1]], src[bounds[i+1]:bounds[i+2]], dst[bounds[i]:bounds[i+2]])
+			next = append(next, bounds[i+2])
+		}
+		// An odd number of runs leaves the last one without a partner
+		if i+1 < len(bounds) {
+			copy(dst[bounds[i]:], src[bounds[i]:])
+			next = append(next, bounds[i+1])
+		}
+		bounds = next
+		src, dst = dst, src
+	}
+	if &src[0] != &items[0] {
+		copy(items, src)
+	}
+}
+
+// isDescending reports whether items is sorted in strictly descending order, so that reversing it does not reorder equal elements.
+func isDescending[T cmp.Ordered](items []T) bool {
+	for i := 1; i < len(items); i++ {
+		if !less(items[i], items[i-1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isInteger reports whether T is one of the integer types supported by radix sort.
+func isInteger[T cmp.Ordered]() bool {
+	var val T
+	switch any(val).(type) {
+	case uint64, uint32, uint16, uint8, uint, uintptr, int64, int32, int16, int8, int:
+		return true
+	}
+	return false
+}
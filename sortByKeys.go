@@ -0,0 +1,108 @@
+package sort
+
+import (
+	"cmp"
+	"unsafe"
+)
+
+// Key describes one of the keys used by SortByKeys to sort items of type T.
+// Keys are created using By and ByDesc.
+type Key[T any] struct {
+	compare func(a, b T) int
+	// radix maps integer keys to unsigned integers with the same order and is nil for all other types
+	radix func(T) uint64
+}
+
+// By returns a key sorting items in ascending order of the value returned by key.
+func By[T any, K cmp.Ordered](key func(T) K) Key[T] {
+	return Key[T]{
+		compare: func(a, b T) int { return cmp.Compare(key(a), key(b)) },
+		radix:   radixKey(key),
+	}
+}
+
+// ByDesc returns a key sorting items in descending order of the value returned by key.
+func ByDesc[T any, K cmp.Ordered](key func(T) K) Key[T] {
+	k := Key[T]{compare: func(a, b T) int { return cmp.Compare(key(b), key(a)) }}
+	// Inverting all bits reverses the order of the unsigned integers
+	if radix := radixKey(key); radix != nil {
+		k.radix = func(v T) uint64 { return ^radix(v) }
+	}
+	return k
+}
+
+// SortByKeys sorts items lexicographically by multiple keys, comparing by the first key and only using the following ones to order items with equal keys.
+// The sort is stable, therefore items for which all keys are equal retain their original order.
+// If all keys are integers, successive radix sort passes are performed from the last to the first key.
+// Otherwise the items are sorted using merge sort with a comparison function combining all keys.
+func SortByKeys[T any](items []T, keys ...Key[T]) []T {
+	if len(items) < 2 || len(keys) == 0 {
+		return items
+	}
+	radix := len(items) >= CurrentThresholds().RadixStable
+	for _, key := range keys {
+		radix = radix && key.radix != nil
+	}
+	if !radix {
+		return MergeSortFunc(items, func(a, b T) int {
+			for _, key := range keys {
+				if c := key.compare(a, b); c != 0 {
+					return c
+				}
+			}
+			return 0
+		})
+	}
+
+	// Sort a permutation of the items since moving the items themselves in every pass would be expensive
+	perm := make([]int, len(items))
+	for i := range perm {
+		perm[i] = i
+	}
+	values := make([]uint64, len(items))
+	// Every pass is stable, so sorting by the most significant key last keeps the order established by the others for equal keys
+	for k := len(keys) - 1; k >= 0; k-- {
+		for i, p := range perm {
+			values[i] = keys[k].radix(items[p])
+		}
+		radixSortUintPairs(values, perm, nil, nil)
+	}
+
+	sorted := make([]T, len(items))
+	for i, p := range perm {
+		sorted[i] = items[p]
+	}
+	copy(items, sorted)
+	return items
+}
+
+// radixKey returns a function mapping the integer key of an item to an unsigned integer with the same order or nil if the key is not an integer.
+// Signed integers are sign extended to 64 bits before their sign bit is flipped, so keys of all sizes are ordered as uint64.
+func radixKey[T any, K cmp.Ordered](key func(T) K) func(T) uint64 {
+	var val K
+	switch any(val).(type) {
+	case uint64:
+		return func(v T) uint64 { k := key(v); return *(*uint64)(unsafe.Pointer(&k)) }
+	case uint32:
+		return func(v T) uint64 { k := key(v); return uint64(*(*uint32)(unsafe.Pointer(&k))) }
+	case uint16:
+		return func(v T) uint64 { k := key(v); return uint64(*(*uint16)(unsafe.Pointer(&k))) }
+	case uint8:
+		return func(v T) uint64 { k := key(v); return uint64(*(*uint8)(unsafe.Pointer(&k))) }
+	case uint:
+		return func(v T) uint64 { k := key(v); return uint64(*(*uint)(unsafe.Pointer(&k))) }
+	case uintptr:
+		return func(v T) uint64 { k := key(v); return uint64(*(*uintptr)(unsafe.Pointer(&k))) }
+	case int64:
+		return func(v T) uint64 { k := key(v); return uint64(*(*int64)(unsafe.Pointer(&k))) ^ 1<<63 }
+	case int32:
+		return func(v T) uint64 { k := key(v); return uint64(int64(*(*int32)(unsafe.Pointer(&k)))) ^ 1<<63 }
+	case int16:
+		return func(v T) uint64 { k := key(v); return uint64(int64(*(*int16)(unsafe.Pointer(&k)))) ^ 1<<63 }
+	case int8:
+		return func(v T) uint64 { k := key(v); return uint64(int64(*(*int8)(unsafe.Pointer(&k)))) ^ 1<<63 }
+	case int:
+		return func(v T) uint64 { k := key(v); return uint64(int64(*(*int)(unsafe.Pointer(&k)))) ^ 1<<63 }
+	}
+	return nil
+}
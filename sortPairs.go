@@ -0,0 +1,92 @@
+package sort
+
+import "cmp"
+
+// SortPairs sorts a slice of keys and reorders a companion slice of values identically, keeping the element at each index together with its key.
+// This allows sorting data stored as separate columns without first combining it into a slice of structs.
+// The sort is stable, therefore values with the same key retain their original order.
+// Integer keys are sorted using radix sort with the values being moved in the same pass, all other types use merge sort.
+// Both slices are updated in-place and returned for convenience as well.
+// SortPairs panics if the slices do not have the same length.
+func SortPairs[K cmp.Ordered, V any](keys []K, vals []V) ([]K, []V) {
+	if len(keys) != len(vals) {
+		panic("sort: SortPairs called with slices of different lengths")
+	}
+	if len(keys) < 2 {
+		return keys, vals
+	}
+	switch k := any(keys).(type) {
+	case []uint64:
+		radixSortUintPairs(k, vals, nil, nil)
+	case []uint32:
+		radixSortUintPairs(k, vals, nil, nil)
+	case []uint16:
+		radixSortUintPairs(k, vals, nil, nil)
+	case []uint8:
+		radixSortUintPairs(k, vals, nil, nil)
+	case []uint:
+		radixSortUintPairs(k, vals, nil, nil)
+	case []uintptr:
+		radixSortUintPairs(k, vals, nil, nil)
+	case []int64:
+		radixSortIntPairs[int64, uint64](k, vals)
+	case []int32:
+		radixSortIntPairs[int32, uint32](k, vals)
+	case []int16:
+		radixSortIntPairs[int16, uint16](k, vals)
+	case []int8:
+		radixSortIntPairs[int8, uint8](k, vals)
+	case []int:
+		radixSortIntPairs[int, uint](k, vals)
+	default:
+		tmpK := make([]K, len(keys))
+		tmpV := make([]V, len(vals))
+		copy(tmpK, keys)
+		copy(tmpV, vals)
+		mergeSortPairs(tmpK, tmpV, keys, vals)
+	}
+	return keys, vals
+}
+
+// mergeSortPairs is the equivalent of mergeSort for pairs of keys and values stored in separate slices.
+func mergeSortPairs[K cmp.Ordered, V any](srcK []K, srcV []V, dstK []K, dstV []V) {
+	if len(srcK) < 2 {
+		return
+	}
+
+	// Find the midpoint
+	mid := len(srcK) / 2
+
+	// Recursively sort the two halves with swapped src and dst
+	mergeSortPairs(dstK[:mid], dstV[:mid], srcK[:mid], srcV[:mid])
+	mergeSortPairs(dstK[mid:], dstV[mid:], srcK[mid:], srcV[mid:])
+
+	// Merge the sorted halves from src into dst
+	mergeSortedPairs(srcK[:mid], srcV[:mid], srcK[mid:], srcV[mid:], dstK, dstV)
+}
+
+// mergeSortedPairs is the equivalent of mergeSortedSets for pairs of keys and values stored in separate slices.
+func mergeSortedPairs[K cmp.Ordered, V any](aK []K, aV []V, bK []K, bV []V, bufK []K, bufV []V) {
+	length := len(aK) + len(bK)
+	aPos := 0
+	bPos := 0
+	for i := range length {
+		if !less(bK[bPos], aK[aPos]) {
+			bufK[i], bufV[i] = aK[aPos], aV[aPos]
+			aPos++
+			if aPos == len(aK) {
+				copy(bufK[i+1:], bK[bPos:])
+				copy(bufV[i+1:], bV[bPos:])
+				return
+			}
+		} else {
+			bufK[i], bufV[i] = bK[bPos], bV[bPos]
+			bPos++
+			if bPos == len(bK) {
+				copy(bufK[i+1:], aK[aPos:])
+				copy(bufV[i+1:], aV[aPos:])
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package sort
+
+import (
+	"cmp"
+	"math"
+	"math/bits"
+	"math/rand/v2"
+)
+
+// IsSorted reports whether items is sorted in ascending order, with NaNs being ordered first just like by all sorting functions of this package.
+func IsSorted[T cmp.Ordered](items []T) bool {
+	return sortedPrefix(items, len(items)) == len(items)
+}
+
+// IsSortedFunc is the equivalent of IsSorted for any type of data, using the comparison function cmp to determine the order.
+func IsSortedFunc[T any](items []T, cmp func(a, b T) int) bool {
+	for i := 1; i < len(items); i++ {
+		if cmp(items[i-1], items[i]) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsStrictlySorted reports whether items is sorted in strictly ascending order, meaning it is sorted and does not contain any duplicates.
+func IsStrictlySorted[T cmp.Ordered](items []T) bool {
+	for i := 1; i < len(items); i++ {
+		if !less(items[i-1], items[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Sortedness describes how much of a slice is already sorted in ascending order, which determines how well adaptive algorithms like insertion sort perform.
+type Sortedness struct {
+	// Runs is the number of ascending runs, which is 1 for sorted slices and the length of the slice for strictly descending ones.
+	Runs int
+	// Inversions is the number of pairs of items in the wrong order, which is the number of moves performed by insertion sort.
+	// It is 0 for sorted slices and n(n-1)/2 for strictly descending ones.
+	Inversions int
+	// SortedPrefix is the length of the longest sorted prefix.
+	SortedPrefix int
+}
+
+// MeasureSortedness determines the sortedness of items exactly.
+// Counting the inversions requires merge sorting a copy of the items, so it takes O(n log n) time and O(n) additional memory.
+func MeasureSortedness[T cmp.Ordered](items []T) Sortedness {
+	if len(items) == 0 {
+		return Sortedness{}
+	}
+	s := Sortedness{Runs: 1, SortedPrefix: sortedPrefix(items, len(items))}
+	for i := 1; i < len(items); i++ {
+		if less(items[i], items[i-1]) {
+			s.Runs++
+		}
+	}
+	if s.Runs > 1 {
+		src := make([]T, len(items))
+		copy(src, items)
+		s.Inversions = countInversions(src, make([]T, len(items)))
+	}
+	return s
+}
+
+// exactSortednessSize is the maximum length of a slice for which EstimateSortedness measures the sortedness exactly.
+const exactSortednessSize = 256
+
+// EstimateSortedness estimates the sortedness of items by only looking at O(√n) items, which is suitable for deciding how to sort huge slices.
+// Runs and inversions are extrapolated from the number of descents among randomly sampled adjacent items and the number of inverted pairs among randomly sampled pairs.
+// The sorted prefix is determined exactly for up to √n items. If it is longer, √n evenly spaced adjacent pairs are checked, so descents between them can be missed.
+// The samples are chosen deterministically, so the result is the same for the same items. Small slices are measured exactly.
+func EstimateSortedness[T cmp.Ordered](items []T) Sortedness {
+	if len(items) <= exactSortednessSize {
+		return MeasureSortedness(items)
+	}
+	samples := int(math.Sqrt(float64(len(items))))
+	descents, inverted := sampleSortedness(items, samples)
+	n := float64(len(items))
+	s := Sortedness{
+		Runs:       1 + int(math.Round(float64(descents)*(n-1)/float64(samples))),
+		Inversions: int(math.Round(float64(inverted) * n * (n - 1) / 2 / float64(samples))),
+	}
+
+	s.SortedPrefix = sortedPrefix(items, samples)
+	if s.SortedPrefix < samples {
+		return s
+	}
+	stride := max(1, (len(items)-s.SortedPrefix)/samples)
+	for i := s.SortedPrefix; i < len(items); i += stride {
+		if less(items[i], items[i-1]) {
+			s.SortedPrefix = i
+			return s
+		}
+	}
+	s.SortedPrefix = len(items)
+	return s
+}
+
+// sampleSortedness returns the number of descents among randomly sampled adjacent items and the number of inverted pairs among randomly sampled pairs of items.
+// The samples only depend on the length of items and their number.
+// The generator is used directly instead of through a rand.Rand, which would have to be allocated on the heap on every call.
+func sampleSortedness[T cmp.Ordered](items []T, samples int) (descents, inverted int) {
+	n := len(items)
+	var random rand.PCG
+	random.Seed(uint64(n), uint64(samples))
+	// intN returns a random integer in [0, m), mapping the random bits to the range by multiplication instead of a division
+	intN := func(m int) int {
+		hi, _ := bits.Mul64(random.Uint64(), uint64(m))
+		return int(hi)
+	}
+	for range samples {
+		i := intN(n - 1)
+		if less(items[i+1], items[i]) {
+			descents++
+		}
+		a, b := intN(n), intN(n-1)
+		// Skipping a in the range of b makes all pairs of distinct items equally likely
+		if b >= a {
+			b++
+		}
+		if a > b {
+			a, b = b, a
+		}
+		if less(items[b], items[a]) {
+			inverted++
+		}
+	}
+	return descents, inverted
+}
+
+// sortedPrefix returns the length of the longest sorted prefix of items, checking at most limit items.
+func sortedPrefix[T cmp.Ordered](items []T, limit int) int {
+	limit = min(limit, len(items))
+	for i := 1; i < limit; i++ {
+		if less(items[i], items[i-1]) {
+			return i
+		}
+	}
+	return limit
+}
+
+// countInversions sorts src in-place using merge sort while counting the number of inverted pairs.
+// Whenever an item of the second half is merged before the remaining items of the first half, it forms an inversion with each of them.
+// The buffer dst has to have the same length as src.
+func countInversions[T cmp.Ordered](src, dst []T) int {
+	if len(src) < 2 {
+		return 0
+	}
+	mid := len(src) / 2
+	count := countInversions(src[:mid], dst[:mid]) + countInversions(src[mid:], dst[mid:])
+	// The sorted halves are copied to the buffer and merged back into src
+	copy(dst, src)
+	a, b := dst[:mid], dst[mid:]
+	i, j := 0, 0
+	for k := range src {
+		if j == len(b) || (i < len(a) && !less(b[j], a[i])) {
+			src[k] = a[i]
+			i++
+		} else {
+			src[k] = b[j]
+			j++
+			count += len(a) - i
+		}
+	}
+	return count
+}
@@ -0,0 +1,268 @@
+package sort
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// StructError describes why SortStructs cannot sort the given items.
+type StructError struct {
+	// Field is the name of the field causing the error or empty if the error is not caused by a specific field.
+	Field  string
+	Reason string
+}
+
+func (e *StructError) Error() string {
+	if e.Field == "" {
+		return "sort: cannot sort structs: " + e.Reason
+	}
+	return "sort: cannot sort structs by field " + strconv.Quote(e.Field) + ": " + e.Reason
+}
+
+// SortStructs sorts a slice of structs or pointers to structs by their fields, without having to write a comparison function.
+// The fields are given as a comma-separated list of names, e.g. "Name,-CreatedAt", where a leading "-" sorts in descending order.
+// Fields of embedded and nested structs can be used as well, e.g. "Owner.Name", including structs referenced by pointers.
+// Fields behind nil pointers are compared like the zero value of the field.
+// If spec is empty, the fields are taken from struct tags of the form `sort:"1"` or `sort:"2,desc"`, with the number being the priority of the field.
+//
+// Fields can be of any integer, floating point, string or boolean type, or of a type with a method Compare like time.Time.
+// Fields are retrieved only once per item using reflection and the items are sorted using merge sort.
+// The sort is stable, so items with equal fields retain their original order, and nil pointers are ordered before all items.
+// Unknown or unsortable fields are reported as a *StructError before any items are moved.
+func SortStructs(items any, spec string) error {
+	v := reflect.ValueOf(items)
+	if v.Kind() == reflect.Pointer && v.Type().Elem().Kind() == reflect.Slice {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice {
+		return &StructError{Reason: "expected a slice, got " + reflectTypeName(v)}
+	}
+	elem := v.Type().Elem()
+	pointers := elem.Kind() == reflect.Pointer
+	if pointers {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return &StructError{Reason: "expected a slice of structs, got " + v.Type().String()}
+	}
+
+	var keys []structKey
+	var err error
+	if spec == "" {
+		keys, err = structTagKeys(elem)
+	} else {
+		keys, err = structSpecKeys(elem, spec)
+	}
+	if err != nil {
+		return err
+	}
+	if v.Len() < 2 {
+		return nil
+	}
+
+	// Retrieve all structs once, leaving nil pointers as invalid values
+	structs := make([]reflect.Value, v.Len())
+	for i := range structs {
+		structs[i] = v.Index(i)
+		if pointers {
+			structs[i] = structs[i].Elem()
+		}
+	}
+	compares := make([]func(i, j int) int, len(keys))
+	for k, key := range keys {
+		compares[k] = key.column(structs)
+	}
+
+	perm := make([]int, len(structs))
+	for i := range perm {
+		perm[i] = i
+	}
+	MergeSortFunc(perm, func(i, j int) int {
+		if nilI, nilJ := !structs[i].IsValid(), !structs[j].IsValid(); nilI || nilJ {
+			return -cmp.Compare(b2i(nilI), b2i(nilJ))
+		}
+		for k, compare := range compares {
+			if c := compare(i, j); c != 0 {
+				if keys[k].desc {
+					return -c
+				}
+				return c
+			}
+		}
+		return 0
+	})
+
+	sorted := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	for i, p := range perm {
+		sorted.Index(i).Set(v.Index(p))
+	}
+	reflect.Copy(v, sorted)
+	return nil
+}
+
+// structKey describes a field used as a key by SortStructs.
+type structKey struct {
+	name  string
+	index []int
+	desc  bool
+	// column retrieves the field of every struct and returns a function comparing the fields of the structs at two positions.
+	column func(structs []reflect.Value) func(i, j int) int
+}
+
+// structSpecKeys returns the keys listed in a spec like "Name,-CreatedAt".
+func structSpecKeys(typ reflect.Type, spec string) ([]structKey, error) {
+	var keys []structKey
+	for name := range strings.SplitSeq(spec, ",") {
+		name = strings.TrimSpace(name)
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimSpace(strings.TrimLeft(name, "+-"))
+		if name == "" {
+			return nil, &StructError{Reason: "empty field name in " + strconv.Quote(spec)}
+		}
+		key, err := newStructKey(typ, name)
+		if err != nil {
+			return nil, err
+		}
+		key.desc = desc
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// structTagKeys returns the keys of all fields with a sort tag, ordered by their priority.
+func structTagKeys(typ reflect.Type) ([]structKey, error) {
+	type taggedKey struct {
+		priority int
+		key      structKey
+	}
+	var tagged []taggedKey
+	for _, field := range reflect.VisibleFields(typ) {
+		tag, ok := field.Tag.Lookup("sort")
+		if !ok {
+			continue
+		}
+		priority, order, _ := strings.Cut(tag, ",")
+		p, err := strconv.Atoi(strings.TrimSpace(priority))
+		if err != nil {
+			return nil, &StructError{Field: field.Name, Reason: "invalid priority in tag " + strconv.Quote(tag)}
+		}
+		for _, t := range tagged {
+			if t.priority == p {
+				return nil, &StructError{Field: field.Name, Reason: "priority " + strconv.Itoa(p) + " is also used by field " + strconv.Quote(t.key.name)}
+			}
+		}
+		key, err := newStructKey(typ, field.Name)
+		if err != nil {
+			return nil, err
+		}
+		switch strings.TrimSpace(order) {
+		case "", "asc":
+		case "desc":
+			key.desc = true
+		default:
+			return nil, &StructError{Field: field.Name, Reason: "invalid order in tag " + strconv.Quote(tag)}
+		}
+		tagged = append(tagged, taggedKey{p, key})
+	}
+	if len(tagged) == 0 {
+		return nil, &StructError{Reason: "no spec given and " + typ.String() + " has no fields with a sort tag"}
+	}
+	slices.SortFunc(tagged, func(a, b taggedKey) int { return cmp.Compare(a.priority, b.priority) })
+	keys := make([]structKey, len(tagged))
+	for i, t := range tagged {
+		keys[i] = t.key
+	}
+	return keys, nil
+}
+
+// newStructKey looks up the field name, which may refer to nested structs using dots, and creates a key for it.
+func newStructKey(typ reflect.Type, name string) (structKey, error) {
+	key := structKey{name: name}
+	for part := range strings.SplitSeq(name, ".") {
+		// Pointers to nested structs are followed by FieldByIndexErr as well
+		if typ.Kind() == reflect.Pointer && len(key.index) > 0 {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			return key, &StructError{Field: name, Reason: typ.String() + " is not a struct"}
+		}
+		field, ok := typ.FieldByName(part)
+		if !ok {
+			return key, &StructError{Field: name, Reason: "no such field in " + typ.String()}
+		}
+		if !field.IsExported() {
+			return key, &StructError{Field: name, Reason: "field is not exported"}
+		}
+		key.index = append(key.index, field.Index...)
+		typ = field.Type
+	}
+
+	fieldValues := func(structs []reflect.Value) []reflect.Value {
+		values := make([]reflect.Value, len(structs))
+		for i, s := range structs {
+			if !s.IsValid() {
+				continue
+			}
+			// Fields behind nil pointers are compared like the zero value
+			var err error
+			if values[i], err = s.FieldByIndexErr(key.index); err != nil {
+				values[i] = reflect.Zero(typ)
+			}
+		}
+		return values
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		key.column = structColumn(fieldValues, reflect.Value.Int, cmp.Compare[int64])
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		key.column = structColumn(fieldValues, reflect.Value.Uint, cmp.Compare[uint64])
+	case reflect.Float32, reflect.Float64:
+		key.column = structColumn(fieldValues, reflect.Value.Float, cmp.Compare[float64])
+	case reflect.String:
+		key.column = structColumn(fieldValues, reflect.Value.String, cmp.Compare[string])
+	case reflect.Bool:
+		key.column = structColumn(fieldValues, func(v reflect.Value) int { return b2i(v.Bool()) }, cmp.Compare[int])
+	default:
+		method, ok := typ.MethodByName("Compare")
+		if !ok || method.Type.NumIn() != 2 || method.Type.In(1) != typ || method.Type.NumOut() != 1 || method.Type.Out(0).Kind() != reflect.Int {
+			return key, &StructError{Field: name, Reason: "type " + typ.String() + " is not ordered and has no method Compare(" + typ.String() + ") int"}
+		}
+		key.column = structColumn(fieldValues, func(v reflect.Value) reflect.Value { return v }, func(a, b reflect.Value) int {
+			return int(method.Func.Call([]reflect.Value{a, b})[0].Int())
+		})
+	}
+	return key, nil
+}
+
+// structColumn returns a column function for a key, converting the fields of all structs using get and comparing them using compare.
+// Fields of nil pointers are left as the zero value since they are never compared.
+func structColumn[T any](fieldValues func([]reflect.Value) []reflect.Value, get func(reflect.Value) T, compare func(a, b T) int) func([]reflect.Value) func(i, j int) int {
+	return func(structs []reflect.Value) func(i, j int) int {
+		column := make([]T, len(structs))
+		for i, v := range fieldValues(structs) {
+			if v.IsValid() {
+				column[i] = get(v)
+			}
+		}
+		return func(i, j int) int { return compare(column[i], column[j]) }
+	}
+}
+
+// reflectTypeName returns the name of the type of v, which is "nil" for invalid values.
+func reflectTypeName(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	return v.Type().String()
+}
+
+// b2i converts a boolean to an integer, ordering false before true.
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,73 @@
+package sort
+
+import (
+	"math"
+	"time"
+	"unsafe"
+)
+
+// SortTimes sorts times chronologically using radix sort on their nanoseconds as int64 keys.
+// If all times carry a monotonic clock reading, such as times returned by time.Now, they are ordered by it, just like time.Time.Compare does.
+// Otherwise the wall clock readings are used.
+func SortTimes(items []time.Time) []time.Time {
+	return SortByTime(items, func(t time.Time) time.Time { return t })
+}
+
+// SortByTime sorts items by a time key chronologically, e.g. events by their timestamp.
+// The key is retrieved only once per item and the order is the same as for SortTimes.
+// The sort is stable, therefore items with equal times retain their original order.
+func SortByTime[T any](items []T, key func(T) time.Time) []T {
+	if len(items) < 2 {
+		return items
+	}
+	times := make([]time.Time, len(items))
+	for i, v := range items {
+		times[i] = key(v)
+	}
+	if keys, ok := timeKeys(times); ok {
+		radixSortIntPairs[int64, uint64](keys, items)
+		return items
+	}
+	// Times too far from the Unix epoch for nanoseconds to fit in an int64 need a 128-bit key consisting of seconds and nanoseconds
+	keys := make([]Uint128, len(times))
+	for i, t := range times {
+		keys[i] = uint128Key(Int128{Hi: t.Unix(), Lo: uint64(t.Nanosecond())})
+	}
+	radixSortUint128Pairs(keys, items)
+	return items
+}
+
+// SortDurations sorts durations using radix sort.
+// It is equivalent to calling RadixSort on the durations converted to int64, which RadixSort cannot do by itself for named types.
+func SortDurations(items []time.Duration) []time.Duration {
+	RadixSort(unsafe.Slice((*int64)(unsafe.SliceData(items)), len(items)))
+	return items
+}
+
+// maxUnixNanoSeconds is the largest number of seconds from the Unix epoch for which the nanoseconds of a time are guaranteed to fit in an int64.
+const maxUnixNanoSeconds = math.MaxInt64/int64(time.Second) - 1
+
+// timeKeys returns int64 keys with the same order as times or false if the times cannot be represented by such keys.
+// If all times have a monotonic clock reading, the keys are the durations from the first time, which time.Time.Sub calculates using the monotonic clock.
+// Otherwise the keys are the nanoseconds from the Unix epoch.
+func timeKeys(times []time.Time) ([]int64, bool) {
+	keys := make([]int64, len(times))
+	monotonic := true
+	for _, t := range times {
+		// Rounding to 0 strips the monotonic clock reading without changing anything else
+		monotonic = monotonic && t != t.Round(0)
+	}
+	if monotonic {
+		for i, t := range times {
+			keys[i] = int64(t.Sub(times[0]))
+		}
+		return keys, true
+	}
+	for i, t := range times {
+		if s := t.Unix(); s < -maxUnixNanoSeconds || s > maxUnixNanoSeconds {
+			return nil, false
+		}
+		keys[i] = t.UnixNano()
+	}
+	return keys, true
+}